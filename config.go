@@ -0,0 +1,281 @@
+// config.go - .linkchecker.yaml configuration file
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	"golang.org/x/net/http/httpguts"
+	"gopkg.in/yaml.v3"
+
+	"linkchecker/linkcheck"
+)
+
+// configNames are the file names looked up in the working directory and its parents
+var configNames = []string{".linkchecker.yaml", ".linkchecker.yml"}
+
+// Config is the content of a configuration file
+// Keys other than headers and status are named after the flag they set, and
+// a flag given on the command line overrides its key
+type Config struct {
+	Timeout         *time.Duration `yaml:"timeout,omitempty"`
+	MaxTime         *time.Duration `yaml:"max-time,omitempty"`
+	Depth           *int           `yaml:"depth,omitempty"`
+	MaxPages        *int           `yaml:"max-pages,omitempty"`
+	MaxExternal     *int           `yaml:"max-external,omitempty"`
+	Concurrency     *int           `yaml:"concurrency,omitempty"`
+	Retries         *int           `yaml:"retries,omitempty"`
+	RetryBackoff    *time.Duration `yaml:"retry-backoff,omitempty"`
+	MaxRedirects    *int           `yaml:"max-redirects,omitempty"`
+	HostConcurrency *int           `yaml:"host-concurrency,omitempty"`
+	GetOnly         []string       `yaml:"get-only,omitempty"`
+	Rate            []string       `yaml:"rate,omitempty"`
+	UserAgent       *string        `yaml:"user-agent,omitempty"`
+	IgnoreRobots    *bool          `yaml:"ignore-robots,omitempty"`
+	Check           []string       `yaml:"check,omitempty"`
+	BaseURL         *string        `yaml:"base-url,omitempty"`
+	IncludeCode     *bool          `yaml:"include-code,omitempty"`
+	Format          *string        `yaml:"format,omitempty"`
+	Quiet           *bool          `yaml:"quiet,omitempty"`
+	FailOn          *string        `yaml:"fail-on,omitempty"`
+	Slow            *time.Duration `yaml:"slow,omitempty"`
+	Exclude         []string       `yaml:"exclude,omitempty"`
+	ExcludeFile     []string       `yaml:"exclude-file,omitempty"`
+	Include         []string       `yaml:"include,omitempty"`
+	IncludeFile     []string       `yaml:"include-file,omitempty"`
+
+	Headers map[string]string `yaml:"headers,omitempty"` // sent with every request, values expand ${VAR}
+	Status  []StatusConfig    `yaml:"status,omitempty"`  // status codes that are ok or a warning per host or URL
+
+	statusRules []linkcheck.StatusRule
+}
+
+// StatusConfig is a status rule for a host or a URL pattern
+// Codes are written as 403, ranges as 200-299 or 2xx
+type StatusConfig struct {
+	Host    string   `yaml:"host,omitempty"`
+	URL     string   `yaml:"url,omitempty"`
+	OK      []string `yaml:"ok,flow,omitempty"`
+	Warning []string `yaml:"warning,flow,omitempty"`
+}
+
+// findConfig returns the first configuration file in dir or one of its
+// parents, or "" if there is none
+func findConfig(dir string) string {
+	for {
+		for _, name := range configNames {
+			file := filepath.Join(dir, name)
+			if info, err := os.Stat(file); err == nil && info.Mode().IsRegular() {
+				return file
+			}
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// loadConfig reads and validates a configuration file
+// Unknown keys are rejected, so a misspelt setting is not silently ignored
+func loadConfig(file string) (*Config, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	cfg := &Config{}
+	decoder := yaml.NewDecoder(f)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	if err := cfg.compile(); err != nil {
+		return nil, err
+	}
+
+	// pattern files are relative to the config file, not the working directory
+	for _, files := range [][]string{cfg.ExcludeFile, cfg.IncludeFile} {
+		for i, name := range files {
+			if !filepath.IsAbs(name) {
+				files[i] = filepath.Join(filepath.Dir(file), name)
+			}
+		}
+	}
+	return cfg, nil
+}
+
+// compile validates the settings that are not flags and compiles their patterns
+func (c *Config) compile() error {
+	for name := range c.Headers {
+		if !httpguts.ValidHeaderFieldName(name) {
+			return fmt.Errorf("headers: invalid header name %q", name)
+		}
+	}
+
+	for i, sc := range c.Status {
+		rule, err := sc.rule()
+		if err != nil {
+			return fmt.Errorf("status[%d]: %w", i, err)
+		}
+		c.statusRules = append(c.statusRules, rule)
+	}
+	return nil
+}
+
+// rule compiles a status rule
+func (sc StatusConfig) rule() (linkcheck.StatusRule, error) {
+	var rule linkcheck.StatusRule
+	switch {
+	case (sc.Host == "") == (sc.URL == ""):
+		return rule, errors.New("exactly one of host and url is required")
+	case len(sc.OK) == 0 && len(sc.Warning) == 0:
+		return rule, errors.New("ok or warning status codes are required")
+	case sc.Host != "":
+		rule.Host = strings.ToLower(sc.Host)
+	default:
+		p, err := linkcheck.CompilePattern(sc.URL)
+		if err != nil {
+			return rule, err
+		}
+		rule.URL = &p
+	}
+
+	var err error
+	if rule.OK, err = linkcheck.ParseStatusRanges(sc.OK); err != nil {
+		return rule, err
+	}
+	rule.Warning, err = linkcheck.ParseStatusRanges(sc.Warning)
+	return rule, err
+}
+
+// apply sets every flag the file configures that was not given on the command line
+func (c *Config) apply(fs *flag.FlagSet) error {
+	given := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { given[f.Name] = true })
+
+	v := reflect.ValueOf(c).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		name := configKey(v.Type().Field(i))
+		field := v.Field(i)
+		if fs.Lookup(name) == nil || given[name] || field.IsNil() {
+			continue
+		}
+
+		// a list sets a repeatable flag once per item
+		var values []string
+		if field.Kind() == reflect.Slice {
+			for j := 0; j < field.Len(); j++ {
+				values = append(values, fmt.Sprint(field.Index(j).Interface()))
+			}
+		} else {
+			values = append(values, fmt.Sprint(field.Elem().Interface()))
+		}
+
+		for _, value := range values {
+			if err := fs.Set(name, value); err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// merged returns the settings in effect once the command line has been
+// applied: every flag key holds its final value, headers include -header flags
+func (c *Config) merged(fs *flag.FlagSet, headers http.Header) *Config {
+	merged := *c
+
+	v := reflect.ValueOf(&merged).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		f := fs.Lookup(configKey(v.Type().Field(i)))
+		if f == nil {
+			continue
+		}
+		getter, ok := f.Value.(flag.Getter)
+		if !ok {
+			continue
+		}
+
+		field := v.Field(i)
+		value := reflect.ValueOf(getter.Get())
+		if field.Kind() == reflect.Pointer {
+			p := reflect.New(field.Type().Elem())
+			p.Elem().Set(value.Convert(field.Type().Elem()))
+			field.Set(p)
+		} else {
+			field.Set(value.Convert(field.Type()))
+		}
+	}
+
+	if len(headers) > 0 {
+		merged.Headers = make(map[string]string)
+		for name, value := range c.Headers {
+			merged.Headers[http.CanonicalHeaderKey(name)] = value
+		}
+		for name := range headers {
+			merged.Headers[name] = headers.Get(name)
+		}
+	}
+	return &merged
+}
+
+// header returns the configured request headers with environment variables
+// expanded, overridden by the -header flags
+func (c *Config) header(flagHeaders http.Header) http.Header {
+	header := make(http.Header)
+	for name, value := range c.Headers {
+		header.Set(name, os.ExpandEnv(value))
+	}
+	for name, values := range flagHeaders {
+		header[name] = values
+	}
+	return header
+}
+
+// configKey returns the YAML key of a Config field
+func configKey(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+	return name
+}
+
+// headerList is a flag that can be repeated to collect "Name: value" request headers
+type headerList struct {
+	http.Header
+}
+
+// String formats the headers as "Name: value" pairs
+func (l *headerList) String() string {
+	var pairs []string
+	for name := range l.Header {
+		pairs = append(pairs, name+": "+l.Get(name))
+	}
+	return strings.Join(pairs, ", ")
+}
+
+// Set adds a "Name: value" header, replacing an earlier value of the same name
+func (l *headerList) Set(value string) error {
+	name, val, found := strings.Cut(value, ":")
+	name = strings.TrimSpace(name)
+	if !found || !httpguts.ValidHeaderFieldName(name) {
+		return fmt.Errorf("invalid header %q, expected \"Name: value\"", value)
+	}
+
+	if l.Header == nil {
+		l.Header = make(http.Header)
+	}
+	l.Header.Set(name, strings.TrimSpace(val))
+	return nil
+}
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"flag"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"linkchecker/linkcheck"
+)
+
+func TestFindConfig(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "docs", "guide")
+	if err := os.MkdirAll(nested, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := findConfig(nested); got != "" && strings.HasPrefix(got, root) {
+		t.Errorf("findConfig() = %q before any file exists", got)
+	}
+
+	file := filepath.Join(root, ".linkchecker.yml")
+	if err := os.WriteFile(file, []byte("timeout: 5s\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := findConfig(nested); got != file {
+		t.Errorf("findConfig() = %q, want %q from a parent directory", got, file)
+	}
+
+	closer := filepath.Join(root, "docs", ".linkchecker.yaml")
+	if err := os.WriteFile(closer, []byte("timeout: 5s\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := findConfig(nested); got != closer {
+		t.Errorf("findConfig() = %q, want the closest file %q", got, closer)
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	file := writeConfig(t, `
+timeout: 30s
+max-time: 10m
+concurrency: 4
+check: [links, images]
+fail-on: warning
+headers:
+  Authorization: Bearer ${LINKCHECKER_TEST_TOKEN}
+exclude:
+  - re:^https://localhost
+exclude-file: [patterns.txt]
+status:
+  - host: linkedin.com
+    ok: [2xx, 999]
+    warning: [403]
+  - url: https://example.com/removed/*
+    ok: [404-410]
+`)
+
+	cfg, err := loadConfig(file)
+	if err != nil {
+		t.Fatalf("loadConfig() error = %v", err)
+	}
+
+	if cfg.Timeout == nil || *cfg.Timeout != 30*time.Second {
+		t.Errorf("Timeout = %v, want 30s", cfg.Timeout)
+	}
+	if cfg.MaxTime == nil || *cfg.MaxTime != 10*time.Minute {
+		t.Errorf("MaxTime = %v, want 10m", cfg.MaxTime)
+	}
+	if cfg.Concurrency == nil || *cfg.Concurrency != 4 {
+		t.Errorf("Concurrency = %v, want 4", cfg.Concurrency)
+	}
+	if cfg.FailOn == nil || *cfg.FailOn != "warning" {
+		t.Errorf("FailOn = %v, want warning", cfg.FailOn)
+	}
+	if !slices.Equal(cfg.Check, []string{"links", "images"}) {
+		t.Errorf("Check = %v, want [links images]", cfg.Check)
+	}
+	if cfg.Depth != nil {
+		t.Errorf("Depth = %v, want unset", *cfg.Depth)
+	}
+	if !slices.Equal(cfg.Exclude, []string{"re:^https://localhost"}) {
+		t.Errorf("Exclude = %v, want re:^https://localhost", cfg.Exclude)
+	}
+	if want := filepath.Join(filepath.Dir(file), "patterns.txt"); !slices.Equal(cfg.ExcludeFile, []string{want}) {
+		t.Errorf("ExcludeFile = %v, want %s relative to the config file", cfg.ExcludeFile, want)
+	}
+	if len(cfg.statusRules) != 2 || !slices.Equal(cfg.statusRules[0].OK, []linkcheck.StatusRange{{Min: 200, Max: 299}, {Min: 999, Max: 999}}) ||
+		!slices.Equal(cfg.statusRules[1].OK, []linkcheck.StatusRange{{Min: 404, Max: 410}}) {
+		t.Errorf("statusRules = %+v, want the linkedin.com and removed pages rules", cfg.statusRules)
+	}
+
+	t.Setenv("LINKCHECKER_TEST_TOKEN", "secret")
+	header := cfg.header(http.Header{"X-Extra": {"1"}})
+	if got := header.Get("Authorization"); got != "Bearer secret" {
+		t.Errorf("Authorization = %q, want the environment variable expanded", got)
+	}
+	if got := header.Get("X-Extra"); got != "1" {
+		t.Errorf("X-Extra = %q, want the -header value", got)
+	}
+}
+
+func TestLoadConfig_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "unknown key", content: "timout: 5s\n", wantErr: "field timout not found"},
+		{name: "wrong type", content: "concurrency: many\n", wantErr: "cannot unmarshal"},
+		{name: "status without codes", content: "status: [{url: x}]\n", wantErr: "status[0]: ok or warning"},
+		{name: "status bad code", content: "status: [{host: x, ok: [42]}]\n", wantErr: "status[0]: invalid status \"42\""},
+		{name: "bad header name", content: "headers: {'Bad Name': x}\n", wantErr: "invalid header name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadConfig(writeConfig(t, tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("loadConfig() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadConfig_Empty(t *testing.T) {
+	cfg, err := loadConfig(writeConfig(t, ""))
+	if err != nil {
+		t.Fatalf("loadConfig() error = %v", err)
+	}
+	if cfg.Timeout != nil || len(cfg.Headers) != 0 {
+		t.Errorf("loadConfig() = %+v, want no settings", cfg)
+	}
+}
+
+func TestConfig_Apply(t *testing.T) {
+	cfg, err := loadConfig(writeConfig(t, `
+timeout: 30s
+concurrency: 4
+ignore-robots: true
+get-only: [a.example.com, b.example.com]
+`))
+	if err != nil {
+		t.Fatalf("loadConfig() error = %v", err)
+	}
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	timeout := fs.Duration("timeout", 10*time.Second, "")
+	concurrency := fs.Int("concurrency", 10, "")
+	ignoreRobots := fs.Bool("ignore-robots", false, "")
+	depth := fs.Int("depth", 2, "")
+	var getOnly stringList
+	fs.Var(&getOnly, "get-only", "")
+
+	// flags given on the command line win over the file
+	if err := fs.Parse([]string{"-concurrency", "8"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.apply(fs); err != nil {
+		t.Fatalf("apply() error = %v", err)
+	}
+
+	if *timeout != 30*time.Second {
+		t.Errorf("timeout = %v, want 30s from the file", *timeout)
+	}
+	if *concurrency != 8 {
+		t.Errorf("concurrency = %d, want 8 from the command line", *concurrency)
+	}
+	if !*ignoreRobots {
+		t.Errorf("ignore-robots = false, want true from the file")
+	}
+	if *depth != 2 {
+		t.Errorf("depth = %d, want the default 2", *depth)
+	}
+	if !slices.Equal(getOnly, stringList{"a.example.com", "b.example.com"}) {
+		t.Errorf("get-only = %v, want both hosts from the file", getOnly)
+	}
+
+	merged := cfg.merged(fs, http.Header{"X-Extra": {"1"}})
+	if merged.Concurrency == nil || *merged.Concurrency != 8 {
+		t.Errorf("merged concurrency = %v, want 8", merged.Concurrency)
+	}
+	if merged.Depth == nil || *merged.Depth != 2 {
+		t.Errorf("merged depth = %v, want 2", merged.Depth)
+	}
+	if merged.Headers["X-Extra"] != "1" {
+		t.Errorf("merged headers = %v, want X-Extra from -header", merged.Headers)
+	}
+}
+
+func TestConfig_ApplyInvalidValue(t *testing.T) {
+	cfg := &Config{Rate: []string{"fast"}}
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	var rates linkcheck.RateLimits
+	fs.Var(&rates, "rate", "")
+
+	if err := cfg.apply(fs); err == nil || !strings.Contains(err.Error(), "rate:") {
+		t.Errorf("apply() error = %v, want an invalid rate error", err)
+	}
+}
+
+func TestHeaderList(t *testing.T) {
+	var headers headerList
+	for _, value := range []string{"Authorization: Bearer x", "x-api-key:abc", "Authorization: Bearer y"} {
+		if err := headers.Set(value); err != nil {
+			t.Fatalf("Set(%q) error = %v", value, err)
+		}
+	}
+
+	if got := headers.Get("Authorization"); got != "Bearer y" {
+		t.Errorf("Authorization = %q, want the last value", got)
+	}
+	if got := headers.Get("X-Api-Key"); got != "abc" {
+		t.Errorf("X-Api-Key = %q, want abc", got)
+	}
+
+	for _, value := range []string{"no colon", ": empty", "bad name: x"} {
+		if err := headers.Set(value); err == nil {
+			t.Errorf("Set(%q) error = nil, want an error", value)
+		}
+	}
+}
+
+func TestStatusConfig_Rule(t *testing.T) {
+	rule, err := StatusConfig{Host: "LinkedIn.com", OK: []string{"2xx", "999"}}.rule()
+	if err != nil {
+		t.Fatalf("rule() error = %v", err)
+	}
+	if rule.Host != "linkedin.com" || len(rule.OK) != 2 || rule.URL != nil {
+		t.Errorf("rule() = %+v, want host linkedin.com with two ok ranges", rule)
+	}
+
+	tests := []struct {
+		name    string
+		config  StatusConfig
+		wantErr string
+	}{
+		{name: "no target", config: StatusConfig{OK: []string{"200"}}, wantErr: "exactly one of host and url"},
+		{name: "both targets", config: StatusConfig{Host: "a", URL: "b", OK: []string{"200"}}, wantErr: "exactly one of host and url"},
+		{name: "no codes", config: StatusConfig{Host: "a"}, wantErr: "status codes are required"},
+		{name: "bad code", config: StatusConfig{Host: "a", Warning: []string{"4x"}}, wantErr: "invalid status"},
+		{name: "bad pattern", config: StatusConfig{URL: "re:(", OK: []string{"200"}}, wantErr: "invalid pattern"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.config.rule(); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("rule() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// writeConfig writes a config file to a temporary directory and returns its path
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), ".linkchecker.yaml")
+	if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
@@ -3,3 +3,12 @@ module linkchecker
 go 1.25.1
 
 require golang.org/x/net v0.49.0
+
+require golang.org/x/time v0.15.0
+
+require github.com/yuin/goldmark v1.8.2
+
+require (
+	golang.org/x/text v0.33.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1
+)
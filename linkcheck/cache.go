@@ -0,0 +1,173 @@
+// cache.go - Fetchers that keep and replay responses
+
+package linkcheck
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNotRecorded is returned by ReplayFetcher for a URL it has no response for
+var ErrNotRecorded = errors.New("no recorded response")
+
+// RecordedResponse is a response kept in memory by CacheFetcher or ReplayFetcher
+// It can be stored as JSON to replay a check later
+type RecordedResponse struct {
+	Status   int           `json:"status,omitempty"` // 0 means 200
+	Header   http.Header   `json:"header,omitempty"`
+	Body     string        `json:"body,omitempty"`
+	Duration time.Duration `json:"duration,omitempty"`
+}
+
+// response returns the recorded response as the answer to req
+func (r RecordedResponse) response(req *Request) *Response {
+	resp := &Response{
+		URL:      req.URL,
+		Status:   cmp.Or(r.Status, http.StatusOK),
+		Header:   r.Header.Clone(),
+		Body:     http.NoBody,
+		Duration: r.Duration,
+	}
+	if resp.Header == nil {
+		resp.Header = make(http.Header)
+	}
+	if req.Method != http.MethodHead {
+		resp.Body = io.NopCloser(strings.NewReader(r.Body))
+	}
+	return resp
+}
+
+// ReplayFetcher answers from recorded responses, keyed by URL, instead of the
+// network. It serves as an in-memory site in tests and replays a recording
+// made with CacheFetcher.Recording offline. GET and HEAD get the same
+// response, without a body for HEAD
+type ReplayFetcher map[string]RecordedResponse
+
+// Fetch returns the response recorded for the request's URL, without its #fragment
+func (f ReplayFetcher) Fetch(ctx context.Context, req *Request) (*Response, error) {
+	recorded, ok := f[fetchKey(req.URL)]
+	if !ok {
+		return nil, fmt.Errorf("%w for %s", ErrNotRecorded, req.URL)
+	}
+	return recorded.response(req), nil
+}
+
+// CacheFetcher keeps the responses of another Fetcher in memory, so each URL
+// is fetched once however many checks ask for it, also at the same time.
+// Errors are not kept, and bodies are kept up to the size the checker reads
+// of a document
+type CacheFetcher struct {
+	next      Fetcher
+	responses map[string]RecordedResponse // by method and URL
+	inflight  map[string]*cacheCall       // fetches other requests wait for
+	mu        sync.Mutex
+}
+
+// cacheCall is a fetch in flight, done is closed once it has finished
+type cacheCall struct {
+	done     chan struct{}
+	recorded RecordedResponse
+	err      error
+}
+
+// NewCacheFetcher creates an empty cache in front of next
+func NewCacheFetcher(next Fetcher) *CacheFetcher {
+	return &CacheFetcher{
+		next:      next,
+		responses: make(map[string]RecordedResponse),
+		inflight:  make(map[string]*cacheCall),
+	}
+}
+
+// Fetch returns the kept response, or fetches and keeps it
+// A request for a URL already being fetched waits for that fetch
+func (c *CacheFetcher) Fetch(ctx context.Context, req *Request) (*Response, error) {
+	key := req.Method + " " + fetchKey(req.URL)
+
+	c.mu.Lock()
+	if recorded, ok := c.responses[key]; ok {
+		c.mu.Unlock()
+		return recorded.response(req), nil
+	}
+	call, ok := c.inflight[key]
+	if !ok {
+		call = &cacheCall{done: make(chan struct{})}
+		c.inflight[key] = call
+	}
+	c.mu.Unlock()
+
+	if ok {
+		select {
+		case <-call.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	} else {
+		call.recorded, call.err = c.fetch(ctx, req)
+
+		c.mu.Lock()
+		delete(c.inflight, key)
+		if call.err == nil {
+			c.responses[key] = call.recorded
+		}
+		c.mu.Unlock()
+		close(call.done)
+	}
+
+	if call.err != nil {
+		return nil, call.err
+	}
+	return call.recorded.response(req), nil
+}
+
+// fetch fetches with the next fetcher and reads the response to keep it
+func (c *CacheFetcher) fetch(ctx context.Context, req *Request) (RecordedResponse, error) {
+	resp, err := c.next.Fetch(ctx, req)
+	if err != nil {
+		return RecordedResponse{}, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxDocumentSize))
+	if err != nil {
+		return RecordedResponse{}, err
+	}
+	return RecordedResponse{
+		Status:   resp.Status,
+		Header:   resp.Header,
+		Body:     string(body),
+		Duration: resp.Duration,
+	}, nil
+}
+
+// Recording returns the kept responses for a ReplayFetcher
+// A GET response is preferred to a HEAD response for the same URL, as it has the body
+func (c *CacheFetcher) Recording() ReplayFetcher {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	recording := make(ReplayFetcher)
+	for key, recorded := range c.responses {
+		method, u, _ := strings.Cut(key, " ")
+		if _, ok := recording[u]; !ok || method == http.MethodGet {
+			recording[u] = recorded
+		}
+	}
+	return recording
+}
+
+// fetchKey returns the URL a response is kept under, without the #fragment,
+// which is never sent to the server
+func fetchKey(u *url.URL) string {
+	key := *u
+	key.Fragment, key.RawFragment = "", ""
+	return key.String()
+}
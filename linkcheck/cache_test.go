@@ -0,0 +1,126 @@
+package linkcheck
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestReplayFetcher(t *testing.T) {
+	fetcher := ReplayFetcher{
+		"https://example.com/": {Header: http.Header{"Content-Type": {"text/html"}}, Body: "<p>home</p>"},
+	}
+	u := mustParse(t, "https://example.com/")
+
+	resp, err := fetcher.Fetch(t.Context(), &Request{Method: http.MethodGet, URL: u})
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if resp.Status != http.StatusOK || string(body) != "<p>home</p>" {
+		t.Errorf("Fetch(GET) = %d %q, want 200 with the recorded body", resp.Status, body)
+	}
+
+	resp, _ = fetcher.Fetch(t.Context(), &Request{Method: http.MethodHead, URL: u})
+	if body, _ := io.ReadAll(resp.Body); len(body) != 0 {
+		t.Errorf("Fetch(HEAD) body = %q, want none", body)
+	}
+
+	if _, err := fetcher.Fetch(t.Context(), &Request{Method: http.MethodGet, URL: mustParse(t, "https://example.com/#top")}); err != nil {
+		t.Errorf("Fetch(#top) error = %v, want the page recorded without the fragment", err)
+	}
+
+	_, err = fetcher.Fetch(t.Context(), &Request{Method: http.MethodGet, URL: mustParse(t, "https://example.com/other")})
+	if !errors.Is(err, ErrNotRecorded) {
+		t.Errorf("Fetch(unknown) error = %v, want %v", err, ErrNotRecorded)
+	}
+}
+
+func TestCacheFetcher(t *testing.T) {
+	var fetches atomic.Int32
+	next := fetcherFunc(func(req *Request) (*Response, error) {
+		fetches.Add(1)
+		return ReplayFetcher{"https://example.com/": {Body: "<p>home</p>"}}.Fetch(t.Context(), req)
+	})
+	cache := NewCacheFetcher(next)
+
+	for range 3 {
+		if got := checkURL(t.Context(), cache, checkOptions{GetOnlyHosts: []string{"example.com"}}, "https://example.com/"); got.Broken() {
+			t.Fatalf("checkURL() = %+v, want ok", got)
+		}
+	}
+	if n := fetches.Load(); n != 1 {
+		t.Errorf("fetched %d times, want once", n)
+	}
+
+	// a #fragment is not part of the URL fetched
+	checkURL(t.Context(), cache, checkOptions{}, "https://example.com/#intro")
+	if n := fetches.Load(); n != 1 {
+		t.Errorf("fetched %d times, want the page with a fragment served from the cache", n)
+	}
+
+	// errors are not kept
+	checkURL(t.Context(), cache, checkOptions{}, "https://example.com/missing")
+	checkURL(t.Context(), cache, checkOptions{}, "https://example.com/missing")
+	if n := fetches.Load(); n != 3 {
+		t.Errorf("fetched %d times, want the failing URL fetched again", n)
+	}
+
+	// the recording replays without the original fetcher
+	recording := cache.Recording()
+	resp, err := recording.Fetch(t.Context(), &Request{Method: http.MethodGet, URL: mustParse(t, "https://example.com/")})
+	if err != nil {
+		t.Fatalf("Recording().Fetch() error = %v", err)
+	}
+	if body, _ := io.ReadAll(resp.Body); string(body) != "<p>home</p>" {
+		t.Errorf("Recording().Fetch() body = %q, want the cached body", body)
+	}
+}
+
+func TestCacheFetcher_Concurrent(t *testing.T) {
+	var fetches atomic.Int32
+	started, release := make(chan struct{}), make(chan struct{})
+	next := fetcherFunc(func(req *Request) (*Response, error) {
+		fetches.Add(1)
+		close(started)
+		<-release
+		return ReplayFetcher{"https://example.com/": {}}.Fetch(t.Context(), req)
+	})
+	cache := NewCacheFetcher(next)
+	req := &Request{Method: http.MethodGet, URL: mustParse(t, "https://example.com/")}
+
+	// requests arriving while the first is in flight wait for it
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		cache.Fetch(t.Context(), req)
+	}()
+	<-started
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if resp, err := cache.Fetch(t.Context(), req); err != nil || resp.Status != http.StatusOK {
+				t.Errorf("Fetch() = %+v, %v, want the response of the first request", resp, err)
+			}
+		}()
+	}
+	close(release)
+	wg.Wait()
+
+	if n := fetches.Load(); n != 1 {
+		t.Errorf("fetched %d times, want once", n)
+	}
+}
+
+// fetcherFunc adapts a function to the Fetcher interface
+type fetcherFunc func(req *Request) (*Response, error)
+
+func (f fetcherFunc) Fetch(ctx context.Context, req *Request) (*Response, error) {
+	return f(req)
+}
@@ -0,0 +1,266 @@
+// checker.go - URL health checking
+
+package linkcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	maxRetryDelay       = 30 * time.Second // longest Retry-After we are willing to wait
+	DefaultMaxRedirects = 10               // default redirects followed before giving up
+)
+
+var (
+	errTooManyRedirects = errors.New("too many redirects")
+	errRedirectLoop     = errors.New("redirect loop")
+	errStopped          = errors.New("stopped before the check finished")
+)
+
+// checkURL checks if a URL is accessible, retrying transient failures
+// Unless the URL has a #fragment to look up, the body is never used, so HEAD
+// is sent first and GET only when the server rejects HEAD or the host is
+// configured as GET only
+func checkURL(ctx context.Context, fetcher Fetcher, opts checkOptions, targetURL string) LinkResult {
+	if result, ok := opts.excluded(targetURL); ok {
+		return result
+	}
+
+	result := LinkResult{URL: targetURL}
+	_, fragment := splitFragment(targetURL)
+
+	method := http.MethodHead
+	if fragment != "" || opts.isGetOnly(targetURL) {
+		method = http.MethodGet
+	}
+
+	resp, err := fetchURL(ctx, fetcher, opts, method, &result)
+	if err == nil && method == http.MethodHead && rejectsHead(resp.Status) {
+		// the fallback is the same request, not a retry of a flaky link
+		resp.Body.Close()
+		result.Redirects = nil
+		result.Attempts = 0
+		resp, err = fetchURL(ctx, fetcher, opts, http.MethodGet, &result)
+	}
+	if err != nil {
+		result.fail(err)
+		return result
+	}
+	defer resp.Body.Close()
+
+	result.Status = resp.Status
+	opts.classify(&result)
+
+	// a page that loads but lacks the anchor is a broken fragment
+	if fragment != "" && !result.Broken() {
+		if anchors, ok := readAnchors(resp); ok && !anchors[fragment] {
+			result.Fragment = fragment
+			result.Severity = SeverityError
+		}
+	}
+	return result
+}
+
+// fetchURL requests result.URL and follows redirects itself, so every hop
+// is recorded in result.Redirects and a permanent redirect sets result.Warning.
+// Attempts made, including retries, are added to result.Attempts, and the
+// time they took to result.Duration.
+// The caller must close the response body
+func fetchURL(ctx context.Context, fetcher Fetcher, opts checkOptions, method string, result *LinkResult) (*Response, error) {
+	maxRedirects := opts.MaxRedirects
+	if maxRedirects <= 0 {
+		maxRedirects = DefaultMaxRedirects
+	}
+
+	current := result.URL
+	seen := map[string]bool{current: true}
+	retries := 0
+	defer func() { result.Attempts += 1 + retries }()
+
+	for {
+		start := result.Duration
+		resp, attempts, err := fetchWithRetry(ctx, fetcher, opts, method, current, &result.Duration)
+		retries += attempts - 1
+		if err != nil {
+			return nil, err
+		}
+
+		location := resp.Header.Get("Location")
+		if !isRedirect(resp.Status) || location == "" {
+			return resp, nil
+		}
+		resp.Body.Close()
+
+		next, err := resp.URL.Parse(location)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redirect location %q: %w", location, err)
+		}
+		result.Redirects = append(result.Redirects, Redirect{
+			URL:      current,
+			Status:   resp.Status,
+			Location: next.String(),
+			Duration: result.Duration - start,
+		})
+
+		if isPermanentRedirect(resp.Status) && result.Warning == "" {
+			result.Warning = "permanent redirect, update the link"
+		}
+
+		current = next.String()
+		if seen[current] {
+			return nil, fmt.Errorf("%w: %s redirects back to %s", errRedirectLoop, result.URL, current)
+		}
+		seen[current] = true
+
+		if len(result.Redirects) >= maxRedirects {
+			return nil, fmt.Errorf("%w: stopped after %d redirects", errTooManyRedirects, maxRedirects)
+		}
+		if resp.Status == http.StatusSeeOther && method != http.MethodHead {
+			method = http.MethodGet
+		}
+	}
+}
+
+// fetchWithRetry requests a URL, retrying network errors, 429 and 5xx responses
+// up to opts.Retries times. It returns the final response and the number
+// of attempts made; the caller must close the response body.
+// A HEAD the server rejects is returned at once, not retried.
+// The time of each attempt, as the fetcher reports it, and the waits between
+// them are added to elapsed.
+// A request that has been sent is not cancelled with ctx, so checks in flight
+// when a run is stopped still finish, but no further attempt is made, and
+// errStopped is returned if ctx is done while waiting to retry
+func fetchWithRetry(ctx context.Context, fetcher Fetcher, opts checkOptions, method, targetURL string, elapsed *time.Duration) (*Response, int, error) {
+	u, err := url.Parse(targetURL)
+	if err != nil {
+		return nil, 1, err
+	}
+	req := &Request{Method: method, URL: u}
+
+	for attempt := 1; ; attempt++ {
+		start := time.Now()
+		resp, err := fetcher.Fetch(context.WithoutCancel(ctx), req)
+		if err != nil {
+			*elapsed += time.Since(start) // a failed request has no response to time it
+		} else {
+			*elapsed += resp.Duration
+		}
+		if attempt > opts.Retries || ctx.Err() != nil || (err == nil && !isRetryableStatus(resp.Status)) {
+			return resp, attempt, err
+		}
+		// a server without HEAD support gets a GET instead, not a retry
+		if err == nil && method == http.MethodHead && rejectsHead(resp.Status) {
+			return resp, attempt, nil
+		}
+
+		// exponential backoff, unless the server tells us how long to wait
+		delay := opts.RetryBackoff << (attempt - 1)
+		if err == nil {
+			if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+				if retryAfter > maxRetryDelay {
+					return resp, attempt, nil
+				}
+				delay = retryAfter
+			}
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+			resp.Body.Close()
+		}
+		if !sleep(ctx, delay) {
+			return nil, attempt, errStopped
+		}
+		*elapsed += delay
+	}
+}
+
+// sleep waits for d, returning false if ctx is done first
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// isRedirect reports whether a status code redirects to its Location header
+func isRedirect(status int) bool {
+	switch status {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther,
+		http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return true
+	}
+	return false
+}
+
+// isPermanentRedirect reports whether the linking page should be updated
+func isPermanentRedirect(status int) bool {
+	return status == http.StatusMovedPermanently || status == http.StatusPermanentRedirect
+}
+
+// rejectsHead reports whether a HEAD response means the server wants a GET instead
+func rejectsHead(status int) bool {
+	return status == http.StatusMethodNotAllowed ||
+		status == http.StatusNotImplemented ||
+		status == http.StatusForbidden
+}
+
+// isRetryableStatus reports whether a status code is worth retrying
+func isRetryableStatus(status int) bool {
+	return status == http.StatusTooManyRequests || status >= 500
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as an HTTP date
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+	return 0, false
+}
+
+// checkURLs checks multiple URLs in parallel without crawling
+// Results are returned in the same order as urls; once ctx is done, the
+// URLs that were not checked yet are left out
+func checkURLs(ctx context.Context, fetcher Fetcher, urls []string, opts checkOptions) []LinkResult {
+	results := make([]LinkResult, len(urls))
+	checked := make([]bool, len(urls))
+	queue := newWorkQueue(ctx, opts.Concurrency)
+
+	for i, targetURL := range urls {
+		queue.Push(func() {
+			results[i] = checkURL(ctx, fetcher, opts, targetURL)
+			checked[i] = !errors.Is(results[i].Error, errStopped)
+			if checked[i] {
+				opts.emit(results[i])
+			}
+		})
+	}
+
+	queue.Wait()
+	if ctx.Err() == nil {
+		return results
+	}
+
+	var finished []LinkResult
+	for i, result := range results {
+		if checked[i] {
+			finished = append(finished, result)
+		}
+	}
+	return finished
+}
@@ -0,0 +1,772 @@
+package linkcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckURL(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		wantStatus int
+		wantErr    bool
+	}{
+		{
+			name:       "successful request",
+			statusCode: http.StatusOK,
+			wantStatus: http.StatusOK,
+			wantErr:    false,
+		},
+		{
+			name:       "not found",
+			statusCode: http.StatusNotFound,
+			wantStatus: http.StatusNotFound,
+			wantErr:    false,
+		},
+		{
+			name:       "server error",
+			statusCode: http.StatusInternalServerError,
+			wantStatus: http.StatusInternalServerError,
+			wantErr:    false,
+		},
+		{
+			name:       "redirect",
+			statusCode: http.StatusMovedPermanently,
+			wantStatus: http.StatusMovedPermanently,
+			wantErr:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a test server
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.statusCode)
+			}))
+			defer server.Close()
+
+			fetcher := &HTTPFetcher{Client: &http.Client{Timeout: 5 * time.Second}}
+			got := checkURL(t.Context(), fetcher, checkOptions{}, server.URL)
+
+			if (got.Error != nil) != tt.wantErr {
+				t.Errorf("checkURL() error = %v, wantErr %v", got.Error, tt.wantErr)
+				return
+			}
+
+			if got.Status != tt.wantStatus {
+				t.Errorf("checkURL() = %v, want %v", got.Status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestCheckURL_InvalidURL(t *testing.T) {
+	fetcher := &HTTPFetcher{Client: &http.Client{Timeout: 5 * time.Second}}
+	result := checkURL(t.Context(), fetcher, checkOptions{}, "://invalid-url")
+
+	if result.Error == nil {
+		t.Error("checkURL() expected error for invalid URL, got nil")
+	}
+}
+
+func TestCheckURL_Timeout(t *testing.T) {
+	// Create a server that delays response
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// Client with very short timeout
+	fetcher := &HTTPFetcher{Client: &http.Client{Timeout: 10 * time.Millisecond}}
+	result := checkURL(t.Context(), fetcher, checkOptions{}, server.URL)
+
+	if result.Error == nil {
+		t.Error("checkURL() expected timeout error, got nil")
+	}
+}
+
+func TestCheckURL_Retries(t *testing.T) {
+	tests := []struct {
+		name         string
+		failures     int // responses with failStatus before a 200
+		failStatus   int
+		retries      int
+		wantStatus   int
+		wantAttempts int
+		wantBroken   bool
+	}{
+		{
+			name:         "recovers after transient 502",
+			failures:     2,
+			failStatus:   http.StatusBadGateway,
+			retries:      3,
+			wantStatus:   http.StatusOK,
+			wantAttempts: 3,
+		},
+		{
+			name:         "retries 429",
+			failures:     1,
+			failStatus:   http.StatusTooManyRequests,
+			retries:      1,
+			wantStatus:   http.StatusOK,
+			wantAttempts: 2,
+		},
+		{
+			name:         "gives up after retries",
+			failures:     5,
+			failStatus:   http.StatusServiceUnavailable,
+			retries:      2,
+			wantStatus:   http.StatusServiceUnavailable,
+			wantAttempts: 3,
+			wantBroken:   true,
+		},
+		{
+			name:         "does not retry 404",
+			failures:     5,
+			failStatus:   http.StatusNotFound,
+			retries:      3,
+			wantStatus:   http.StatusNotFound,
+			wantAttempts: 1,
+			wantBroken:   true,
+		},
+		{
+			name:         "no retries by default",
+			failures:     1,
+			failStatus:   http.StatusInternalServerError,
+			retries:      0,
+			wantStatus:   http.StatusInternalServerError,
+			wantAttempts: 1,
+			wantBroken:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				if requests <= tt.failures {
+					w.WriteHeader(tt.failStatus)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			fetcher := &HTTPFetcher{Client: &http.Client{Timeout: 5 * time.Second}}
+			opts := checkOptions{Retries: tt.retries, RetryBackoff: time.Millisecond}
+			got := checkURL(t.Context(), fetcher, opts, server.URL)
+
+			if got.Status != tt.wantStatus {
+				t.Errorf("Status = %d, want %d", got.Status, tt.wantStatus)
+			}
+			if got.Attempts != tt.wantAttempts {
+				t.Errorf("Attempts = %d, want %d", got.Attempts, tt.wantAttempts)
+			}
+			if got.Broken() != tt.wantBroken {
+				t.Errorf("Broken() = %v, want %v", got.Broken(), tt.wantBroken)
+			}
+		})
+	}
+}
+
+func TestCheckURL_RetriesNetworkError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	closedURL := server.URL
+	server.Close()
+
+	fetcher := &HTTPFetcher{Client: &http.Client{Timeout: 5 * time.Second}}
+	got := checkURL(t.Context(), fetcher, checkOptions{Retries: 2, RetryBackoff: time.Millisecond}, closedURL)
+
+	if got.Error == nil {
+		t.Fatal("Expected connection error, got nil")
+	}
+	if got.Attempts != 3 {
+		t.Errorf("Attempts = %d, want 3", got.Attempts)
+	}
+}
+
+func TestCheckURL_RetryAfter(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	fetcher := &HTTPFetcher{Client: &http.Client{Timeout: 5 * time.Second}}
+	start := time.Now()
+	got := checkURL(t.Context(), fetcher, checkOptions{Retries: 1, RetryBackoff: time.Millisecond}, server.URL)
+
+	if got.Status != http.StatusOK {
+		t.Errorf("Status = %d, want 200", got.Status)
+	}
+	if elapsed := time.Since(start); elapsed < 900*time.Millisecond {
+		t.Errorf("Retry-After: 1 was not honoured, retried after %v", elapsed)
+	}
+}
+
+func TestCheckURL_RetryAfterTooLong(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	fetcher := &HTTPFetcher{Client: &http.Client{Timeout: 5 * time.Second}}
+	got := checkURL(t.Context(), fetcher, checkOptions{Retries: 3, RetryBackoff: time.Millisecond}, server.URL)
+
+	if got.Attempts != 1 {
+		t.Errorf("Attempts = %d, want 1 when Retry-After exceeds the maximum delay", got.Attempts)
+	}
+}
+
+func TestCheckURL_RetryStopped(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+
+	fetcher := &HTTPFetcher{Client: &http.Client{Timeout: 5 * time.Second}}
+	start := time.Now()
+	got := checkURL(ctx, fetcher, checkOptions{Retries: 3, RetryBackoff: 10 * time.Second}, server.URL)
+
+	if !errors.Is(got.Error, errStopped) {
+		t.Errorf("Error = %v, want %v", got.Error, errStopped)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("the retry backoff was not interrupted, returned after %v", elapsed)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{name: "empty", value: "", wantOK: false},
+		{name: "seconds", value: "120", want: 2 * time.Minute, wantOK: true},
+		{name: "zero", value: "0", want: 0, wantOK: true},
+		{name: "date in the past", value: "Wed, 21 Oct 2015 07:28:00 GMT", want: 0, wantOK: true},
+		{name: "garbage", value: "soon", wantOK: false},
+		{name: "negative", value: "-5", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseRetryAfter(tt.value)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("parseRetryAfter(%q) = %v, %v, want %v, %v", tt.value, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestCheckURL_HeadFirst(t *testing.T) {
+	tests := []struct {
+		name        string
+		headStatus  int
+		getOnly     bool
+		wantMethods string
+		wantStatus  int
+	}{
+		{name: "HEAD succeeds", headStatus: http.StatusOK, wantMethods: "HEAD", wantStatus: http.StatusOK},
+		{name: "HEAD not found", headStatus: http.StatusNotFound, wantMethods: "HEAD", wantStatus: http.StatusNotFound},
+		{name: "fallback on 405", headStatus: http.StatusMethodNotAllowed, wantMethods: "HEAD,GET", wantStatus: http.StatusOK},
+		{name: "fallback on 501", headStatus: http.StatusNotImplemented, wantMethods: "HEAD,GET", wantStatus: http.StatusOK},
+		{name: "fallback on 403", headStatus: http.StatusForbidden, wantMethods: "HEAD,GET", wantStatus: http.StatusOK},
+		{name: "GET only host", headStatus: http.StatusOK, getOnly: true, wantMethods: "GET", wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var methods []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				methods = append(methods, r.Method)
+				if r.Method == http.MethodHead {
+					w.WriteHeader(tt.headStatus)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			opts := checkOptions{}
+			if tt.getOnly {
+				u, _ := url.Parse(server.URL)
+				opts.GetOnlyHosts = []string{u.Hostname()}
+			}
+
+			fetcher := &HTTPFetcher{Client: &http.Client{Timeout: 5 * time.Second}}
+			got := checkURL(t.Context(), fetcher, opts, server.URL)
+
+			if gotMethods := strings.Join(methods, ","); gotMethods != tt.wantMethods {
+				t.Errorf("Methods = %s, want %s", gotMethods, tt.wantMethods)
+			}
+			if got.Status != tt.wantStatus {
+				t.Errorf("Status = %d, want %d", got.Status, tt.wantStatus)
+			}
+			if got.Attempts != 1 {
+				t.Errorf("Attempts = %d, want 1, the GET fallback is not a retry", got.Attempts)
+			}
+		})
+	}
+}
+
+func TestCheckURL_HeadNotImplementedNotRetried(t *testing.T) {
+	var methods []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method)
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusNotImplemented)
+		}
+	}))
+	defer server.Close()
+
+	fetcher := &HTTPFetcher{Client: &http.Client{Timeout: 5 * time.Second}}
+	opts := checkOptions{Retries: 3, RetryBackoff: time.Second}
+
+	start := time.Now()
+	got := checkURL(t.Context(), fetcher, opts, server.URL)
+
+	if gotMethods := strings.Join(methods, ","); gotMethods != "HEAD,GET" {
+		t.Errorf("Methods = %s, want HEAD,GET without retrying the HEAD", gotMethods)
+	}
+	if got.Status != http.StatusOK {
+		t.Errorf("Status = %d, want 200", got.Status)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("checkURL() took %v, want no retry backoff", elapsed)
+	}
+}
+
+func TestCheckURL_RedirectChain(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/old", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/moved", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/moved", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/new", http.StatusFound)
+	})
+	mux.HandleFunc("/new", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	fetcher := &HTTPFetcher{Client: &http.Client{Timeout: 5 * time.Second}}
+	got := checkURL(t.Context(), fetcher, checkOptions{}, server.URL+"/old")
+
+	if got.Status != http.StatusOK {
+		t.Errorf("Status = %d, want 200", got.Status)
+	}
+	if got.Broken() {
+		t.Error("Redirected link should not be broken")
+	}
+	if got.Attempts != 1 {
+		t.Errorf("Attempts = %d, want 1", got.Attempts)
+	}
+
+	want := []Redirect{
+		{URL: server.URL + "/old", Status: http.StatusMovedPermanently, Location: server.URL + "/moved"},
+		{URL: server.URL + "/moved", Status: http.StatusFound, Location: server.URL + "/new"},
+	}
+	if len(got.Redirects) != len(want) {
+		t.Fatalf("Redirects = %v, want %d hops", got.Redirects, len(want))
+	}
+	for i, hop := range want {
+		if got.Redirects[i].URL != hop.URL || got.Redirects[i].Status != hop.Status || got.Redirects[i].Location != hop.Location {
+			t.Errorf("Redirects[%d] = %+v, want %+v", i, got.Redirects[i], hop)
+		}
+	}
+
+	if got.Warning == "" {
+		t.Error("Expected warning for permanent redirect")
+	}
+}
+
+func TestCheckURL_RedirectWarnings(t *testing.T) {
+	tests := []struct {
+		status       int
+		wantWarning  bool
+		wantSeverity Severity
+	}{
+		{status: http.StatusMovedPermanently, wantWarning: true, wantSeverity: SeverityWarning},
+		{status: http.StatusPermanentRedirect, wantWarning: true, wantSeverity: SeverityWarning},
+		{status: http.StatusFound, wantWarning: false, wantSeverity: SeverityInfo},
+		{status: http.StatusSeeOther, wantWarning: false, wantSeverity: SeverityInfo},
+		{status: http.StatusTemporaryRedirect, wantWarning: false, wantSeverity: SeverityInfo},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/" {
+					http.Redirect(w, r, "/target", tt.status)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			fetcher := &HTTPFetcher{Client: &http.Client{Timeout: 5 * time.Second}}
+			got := checkURL(t.Context(), fetcher, checkOptions{}, server.URL+"/")
+
+			if (got.Warning != "") != tt.wantWarning {
+				t.Errorf("Warning = %q, wantWarning %v", got.Warning, tt.wantWarning)
+			}
+			if got.Severity != tt.wantSeverity {
+				t.Errorf("Severity = %q, want %q", got.Severity, tt.wantSeverity)
+			}
+			if len(got.Redirects) != 1 {
+				t.Errorf("Expected 1 redirect, got %d", len(got.Redirects))
+			}
+		})
+	}
+}
+
+func TestCheckURL_Slow(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	fetcher := &HTTPFetcher{Client: &http.Client{Timeout: 5 * time.Second}}
+
+	got := checkURL(t.Context(), fetcher, checkOptions{SlowThreshold: 10 * time.Millisecond}, server.URL)
+	if got.Severity != SeverityWarning || !strings.HasPrefix(got.Warning, "slow response") {
+		t.Errorf("Severity = %q, Warning = %q, want a slow response warning", got.Severity, got.Warning)
+	}
+	if got.Duration < 50*time.Millisecond {
+		t.Errorf("Duration = %v, want at least 50ms", got.Duration)
+	}
+
+	got = checkURL(t.Context(), fetcher, checkOptions{SlowThreshold: 5 * time.Second}, server.URL)
+	if got.Severity != SeverityOK {
+		t.Errorf("Severity = %q, want ok under the threshold", got.Severity)
+	}
+}
+
+func TestCheckURL_FetcherDuration(t *testing.T) {
+	// the timings are the fetcher's, so replayed responses keep their own
+	fetcher := ReplayFetcher{
+		"https://example.com/old": {
+			Status:   http.StatusFound,
+			Header:   http.Header{"Location": {"/new"}},
+			Duration: 2 * time.Second,
+		},
+		"https://example.com/new": {Duration: 3 * time.Second},
+	}
+
+	got := checkURL(t.Context(), fetcher, checkOptions{SlowThreshold: 4 * time.Second}, "https://example.com/old")
+	if got.Duration != 5*time.Second || len(got.Redirects) != 1 || got.Redirects[0].Duration != 2*time.Second {
+		t.Errorf("Duration = %v, redirects %+v, want 5s with a 2s redirect", got.Duration, got.Redirects)
+	}
+	if !strings.HasPrefix(got.Warning, "slow response") {
+		t.Errorf("Warning = %q, want a slow response warning", got.Warning)
+	}
+}
+
+func TestCheckURL_MaxRedirects(t *testing.T) {
+	// /1 -> /2 -> /3 -> ... without end
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/"))
+		http.Redirect(w, r, fmt.Sprintf("/%d", n+1), http.StatusFound)
+	}))
+	defer server.Close()
+
+	fetcher := &HTTPFetcher{Client: &http.Client{Timeout: 5 * time.Second}}
+	got := checkURL(t.Context(), fetcher, checkOptions{MaxRedirects: 3}, server.URL+"/1")
+
+	if !errors.Is(got.Error, errTooManyRedirects) {
+		t.Errorf("Error = %v, want %v", got.Error, errTooManyRedirects)
+	}
+	if !got.Broken() {
+		t.Error("Expected link to be broken")
+	}
+	if len(got.Redirects) != 3 {
+		t.Errorf("Expected 3 recorded redirects, got %d", len(got.Redirects))
+	}
+}
+
+func TestCheckURL_RedirectLoop(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/a", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/b", http.StatusFound)
+	})
+	mux.HandleFunc("/b", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/a", http.StatusFound)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	fetcher := &HTTPFetcher{Client: &http.Client{Timeout: 5 * time.Second}}
+	got := checkURL(t.Context(), fetcher, checkOptions{}, server.URL+"/a")
+
+	if !errors.Is(got.Error, errRedirectLoop) {
+		t.Errorf("Error = %v, want %v", got.Error, errRedirectLoop)
+	}
+	if len(got.Redirects) != 2 {
+		t.Errorf("Expected 2 recorded redirects, got %d", len(got.Redirects))
+	}
+}
+
+func TestCheckURL_Fragment(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/page", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<html><body><h2 id="install">Install</h2><a name="legacy"></a></body></html>`)
+	})
+	mux.HandleFunc("/README.md", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		fmt.Fprint(w, "# Project\n\n## Getting Started\n")
+	})
+	mux.HandleFunc("/file.pdf", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf")
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	tests := []struct {
+		name         string
+		link         string
+		wantBroken   bool
+		wantFragment string
+	}{
+		{name: "HTML id", link: "/page#install", wantBroken: false},
+		{name: "HTML a name", link: "/page#legacy", wantBroken: false},
+		{name: "missing HTML anchor", link: "/page#missing", wantBroken: true, wantFragment: "missing"},
+		{name: "Markdown heading slug", link: "/README.md#getting-started", wantBroken: false},
+		{name: "missing Markdown heading", link: "/README.md#usage", wantBroken: true, wantFragment: "usage"},
+		{name: "non-document target", link: "/file.pdf#page=2", wantBroken: false},
+		{name: "top is always valid", link: "/page#top", wantBroken: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetcher := &HTTPFetcher{Client: &http.Client{Timeout: 5 * time.Second}}
+			got := checkURL(t.Context(), fetcher, checkOptions{}, server.URL+tt.link)
+
+			if got.Broken() != tt.wantBroken {
+				t.Errorf("Broken() = %v, want %v", got.Broken(), tt.wantBroken)
+			}
+			if got.Fragment != tt.wantFragment {
+				t.Errorf("Fragment = %q, want %q", got.Fragment, tt.wantFragment)
+			}
+			if got.Status != http.StatusOK {
+				t.Errorf("Status = %d, want 200", got.Status)
+			}
+		})
+	}
+}
+
+func TestCheckURL_ExcludeAndStatusRules(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	var rules URLRules
+	rules.Exclude.Set("*/private/*")
+	blocked, _ := CompilePattern("*/blocked")
+	opts := checkOptions{
+		Rules:       rules,
+		StatusRules: []StatusRule{{URL: &blocked, OK: []StatusRange{{200, 299}, {403, 403}}}},
+	}
+	fetcher := &HTTPFetcher{Client: &http.Client{Timeout: 5 * time.Second}}
+
+	got := checkURL(t.Context(), fetcher, opts, server.URL+"/private/page")
+	if got.Skipped != SkipExcluded || got.Rule != "exclude */private/*" || got.Broken() || requests != 0 {
+		t.Errorf("excluded URL: Skipped = %q, Rule = %q, Broken() = %v after %d requests, want excluded without a request",
+			got.Skipped, got.Rule, got.Broken(), requests)
+	}
+
+	got = checkURL(t.Context(), fetcher, opts, server.URL+"/blocked")
+	if got.Severity != SeverityOK || got.Status != http.StatusForbidden {
+		t.Errorf("accepted URL: Severity = %q, Status = %d, want ok 403", got.Severity, got.Status)
+	}
+
+	got = checkURL(t.Context(), fetcher, opts, server.URL+"/other")
+	if !got.Broken() {
+		t.Errorf("other URL: Broken() = false, want 403 to be broken")
+	}
+}
+
+func TestCheckURLs(t *testing.T) {
+	// Create test servers with different status codes
+	server200 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server200.Close()
+
+	server404 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server404.Close()
+
+	server500 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server500.Close()
+
+	fetcher := &HTTPFetcher{Client: &http.Client{Timeout: 5 * time.Second}}
+	urls := []string{server200.URL, server404.URL, server500.URL}
+
+	results := checkURLs(t.Context(), fetcher, urls, checkOptions{Concurrency: DefaultConcurrency})
+
+	if len(results) != 3 {
+		t.Fatalf("checkURLs() returned %d results, want 3", len(results))
+	}
+
+	// Verify each result
+	statusMap := make(map[string]int)
+	for _, result := range results {
+		statusMap[result.URL] = result.Status
+	}
+
+	if statusMap[server200.URL] != http.StatusOK {
+		t.Errorf("Expected status 200 for %s, got %d", server200.URL, statusMap[server200.URL])
+	}
+
+	if statusMap[server404.URL] != http.StatusNotFound {
+		t.Errorf("Expected status 404 for %s, got %d", server404.URL, statusMap[server404.URL])
+	}
+
+	if statusMap[server500.URL] != http.StatusInternalServerError {
+		t.Errorf("Expected status 500 for %s, got %d", server500.URL, statusMap[server500.URL])
+	}
+}
+
+func TestCheckURLs_BrokenLinks(t *testing.T) {
+	server404 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server404.Close()
+
+	fetcher := &HTTPFetcher{Client: &http.Client{Timeout: 5 * time.Second}}
+	urls := []string{server404.URL, "http://invalid-domain-that-does-not-exist-12345.com"}
+
+	results := checkURLs(t.Context(), fetcher, urls, checkOptions{Concurrency: DefaultConcurrency})
+
+	brokenCount := 0
+	for _, result := range results {
+		if result.Broken() {
+			brokenCount++
+		}
+	}
+
+	if brokenCount != 2 {
+		t.Errorf("Expected 2 broken links, got %d", brokenCount)
+	}
+}
+
+func TestCheckURLs_PreservesOrder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	urls := make([]string, 25)
+	for i := range urls {
+		urls[i] = fmt.Sprintf("%s/page%d", server.URL, i)
+	}
+
+	fetcher := &HTTPFetcher{Client: &http.Client{Timeout: 5 * time.Second}}
+	results := checkURLs(t.Context(), fetcher, urls, checkOptions{Concurrency: 4})
+
+	if len(results) != len(urls) {
+		t.Fatalf("checkURLs() returned %d results, want %d", len(results), len(urls))
+	}
+
+	for i, result := range results {
+		if result.URL != urls[i] {
+			t.Errorf("results[%d].URL = %q, want %q", i, result.URL, urls[i])
+		}
+	}
+}
+
+func TestCheckURLs_EmptyList(t *testing.T) {
+	fetcher := &HTTPFetcher{Client: &http.Client{Timeout: 5 * time.Second}}
+	results := checkURLs(t.Context(), fetcher, []string{}, checkOptions{Concurrency: DefaultConcurrency})
+
+	if len(results) != 0 {
+		t.Errorf("Expected 0 results for empty URL list, got %d", len(results))
+	}
+}
+
+func TestCheckURLs_Stopped(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	fetcher := &HTTPFetcher{Client: &http.Client{Timeout: 5 * time.Second}}
+	results := checkURLs(ctx, fetcher, []string{server.URL + "/a", server.URL + "/b"}, checkOptions{Concurrency: 1})
+
+	if len(results) != 0 {
+		t.Errorf("checkURLs() = %+v, want no results once the run is stopped", results)
+	}
+}
+
+func BenchmarkCheckURL(b *testing.B) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	fetcher := &HTTPFetcher{Client: &http.Client{Timeout: 5 * time.Second}}
+
+	b.ResetTimer()
+	for b.Loop() {
+		checkURL(b.Context(), fetcher, checkOptions{}, server.URL)
+	}
+}
+
+func BenchmarkCheckURLs(b *testing.B) {
+	servers := make([]*httptest.Server, 5)
+	urls := make([]string, 5)
+
+	for i := range servers {
+		servers[i] = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		urls[i] = servers[i].URL
+	}
+
+	defer func() {
+		for _, server := range servers {
+			server.Close()
+		}
+	}()
+
+	fetcher := &HTTPFetcher{Client: &http.Client{Timeout: 5 * time.Second}}
+
+	b.ResetTimer()
+	for b.Loop() {
+		checkURLs(b.Context(), fetcher, urls, checkOptions{Concurrency: DefaultConcurrency})
+	}
+}
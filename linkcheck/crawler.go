@@ -0,0 +1,278 @@
+// crawler.go - Concurrent web crawling
+
+package linkcheck
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// crawler holds the state shared by every page of a single crawl
+type crawler struct {
+	ctx        context.Context
+	fetcher    Fetcher
+	baseDomain string
+	budget     *crawlBudget
+	opts       checkOptions
+	visited    *safeUrlMap // URLs checked
+	crawled    *safeUrlMap // pages queued to be crawled
+	locations  *locationSet
+	robots     *robotsCache // nil when robots.txt is ignored
+	anchors    *anchorCache
+	queue      *workQueue
+	results    []LinkResult
+	fragments  []fragmentLink // checked once the crawl has finished
+	resultsMu  sync.Mutex
+}
+
+// fragmentLink is a link to a #fragment waiting to be checked against its page
+type fragmentLink struct {
+	link     string
+	page     string
+	fragment string
+}
+
+// crawl crawls startURL and its links using a bounded pool of workers
+func crawl(ctx context.Context, fetcher Fetcher, startURL string, budget *crawlBudget, opts checkOptions) []LinkResult {
+	locations := newLocationSet()
+	c := &crawler{
+		ctx:        ctx,
+		fetcher:    fetcher,
+		baseDomain: startURL,
+		budget:     budget,
+		opts:       opts.withLocations(locations),
+		visited:    &safeUrlMap{visited: make(map[string]bool)},
+		crawled:    &safeUrlMap{visited: make(map[string]bool)},
+		locations:  locations,
+		anchors:    newAnchorCache(fetcher, opts),
+		queue:      newWorkQueue(ctx, opts.Concurrency),
+	}
+	if !budget.opts.IgnoreRobots {
+		c.robots = newRobotsCache(fetcher, budget.opts.UserAgent)
+	}
+
+	c.visited.Visit(startURL)
+	c.crawled.Visit(startURL)
+	c.queuePage(startURL, 0, false)
+	c.queue.Wait()
+
+	c.checkFragments()
+	c.locations.Attach(c.results)
+	return c.results
+}
+
+// queuePage queues a same-domain page for crawlPage
+// The page budget is taken here rather than when the page is fetched, so the
+// queue never holds more pages than MaxPages allows; it and MaxExternal are
+// what bound the memory of a crawl. A page already checked through a resource
+// link has its result, so it is only crawled for its links
+func (c *crawler) queuePage(page string, depth int, checked bool) {
+	// nothing new is queued once the crawl is stopped
+	if c.ctx.Err() != nil {
+		return
+	}
+
+	// excluded pages are neither checked nor followed
+	if result, ok := c.opts.excluded(page); ok {
+		if !checked {
+			c.addResult(result)
+		}
+		return
+	}
+
+	// report pages disallowed by robots.txt instead of fetching them, and as
+	// failed when robots.txt itself could not be fetched
+	if c.robots != nil && !c.robots.Allowed(c.ctx, page) {
+		if !checked {
+			result := LinkResult{URL: page, Skipped: SkipRobots}
+			if err := c.robots.Err(c.ctx, page); err != nil {
+				result = LinkResult{URL: page}
+				result.fail(err)
+			}
+			c.addResult(result)
+		}
+		return
+	}
+
+	// stop once the page budget is spent
+	if !c.budget.TakePage() {
+		return
+	}
+
+	c.queue.Push(func() { c.crawlPage(page, depth, checked) })
+}
+
+// crawlPage checks a same-domain page and queues the links it contains
+// The result of a page that was already checked is not recorded again
+func (c *crawler) crawlPage(targetURL string, depth int, checked bool) {
+	if c.robots != nil && !c.robots.Wait(c.ctx, targetURL) {
+		return
+	}
+
+	// check the URL
+	result := LinkResult{URL: targetURL}
+
+	resp, err := fetchURL(c.ctx, c.fetcher, c.opts, http.MethodGet, &result)
+	if err != nil {
+		result.fail(err)
+		if !checked {
+			c.addResult(result)
+		}
+		return
+	}
+	defer resp.Body.Close()
+
+	result.Status = resp.Status
+	c.opts.classify(&result)
+	if !checked {
+		c.addResult(result)
+	}
+
+	if resp.Status >= 400 {
+		return
+	}
+
+	// links are resolved against the page we ended up on after redirects
+	pageURL := resp.URL
+
+	// only follow links if same domain and within depth limit, with the
+	// extractor for the Content-Type, so documents such as PDFs are not parsed
+	var extractor Extractor
+	if isSameDomain(pageURL.String(), c.baseDomain) && depth < c.budget.opts.MaxDepth {
+		extractor = c.budget.opts.extractor(resp)
+	}
+	kind := documentType(resp)
+	if extractor == nil && kind == "" {
+		return
+	}
+
+	// read the page once for both its anchors and its links
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxDocumentSize))
+	if err != nil {
+		return
+	}
+	if kind != "" {
+		c.anchors.Add(targetURL, documentAnchors(kind, body))
+	}
+	if extractor == nil {
+		return
+	}
+
+	// extract and queue links
+	links, err := extractor.Extract(bytes.NewReader(body), pageURL)
+	if err != nil {
+		return
+	}
+
+	for _, link := range links {
+		// Markdown and URL list pages may hold mailto: and similar links
+		// that the HTML extractor already drops
+		if hasSkippedScheme(link.URL) {
+			continue
+		}
+
+		// suppressed links are reported, but neither checked nor followed
+		if link.Suppressed {
+			if c.budget.opts.checks(link.Kind) {
+				c.addResult(link.SuppressedResult(targetURL))
+			}
+			continue
+		}
+
+		page, fragment := splitFragment(link.URL)
+		sameDomain := isSameDomain(page, c.baseDomain)
+
+		// same-domain pages are crawled even when their kind is not checked,
+		// so the resources of the pages behind them are still found
+		follow := sameDomain && link.navigates()
+		if !follow && !c.budget.opts.checks(link.Kind) {
+			continue
+		}
+
+		// every occurrence is recorded, but each URL is only checked once
+		location := link.At(targetURL)
+		c.locations.Add(page, location)
+
+		if fragment != "" && c.budget.opts.checks(link.Kind) {
+			c.locations.Add(link.URL, location)
+			if !c.visited.Visit(link.URL) {
+				c.addFragment(fragmentLink{link: link.URL, page: page, fragment: fragment})
+			}
+		}
+
+		checked := c.visited.Visit(page)
+		if follow {
+			// crawl same-domain pages one level deeper, also when a resource
+			// link such as <link rel=next> reached the page first
+			if !c.crawled.Visit(page) {
+				c.queuePage(page, depth+1, checked)
+			}
+		} else if checked {
+			continue
+		} else if sameDomain || c.budget.TakeExternal() {
+			// just check resources and external links without following
+			c.queue.Push(func() { c.checkLink(page) })
+		}
+	}
+}
+
+// checkFragments reports fragment links whose page loaded but lacks the anchor
+// Only pages that were checked during the crawl are looked up, so robots.txt
+// and the crawl limits also apply to fragments
+func (c *crawler) checkFragments() {
+	checked := make(map[string]LinkResult)
+	for _, result := range c.results {
+		checked[result.URL] = result
+	}
+
+	queue := newWorkQueue(c.ctx, c.opts.Concurrency)
+	for _, f := range c.fragments {
+		page, ok := checked[f.page]
+		if !ok || page.Broken() || page.Skipped != "" {
+			continue
+		}
+
+		queue.Push(func() {
+			anchors, ok := c.anchors.Anchors(c.ctx, f.page)
+			if !ok || anchors[f.fragment] {
+				return
+			}
+			c.addResult(LinkResult{
+				URL:      f.link,
+				Status:   page.Status,
+				Severity: SeverityError,
+				Fragment: f.fragment,
+			})
+		})
+	}
+	queue.Wait()
+}
+
+// checkLink checks a resource or external link without following it
+func (c *crawler) checkLink(link string) {
+	c.addResult(checkURL(c.ctx, c.fetcher, c.opts, link))
+}
+
+// addFragment records a fragment link to check after the crawl
+func (c *crawler) addFragment(f fragmentLink) {
+	c.resultsMu.Lock()
+	c.fragments = append(c.fragments, f)
+	c.resultsMu.Unlock()
+}
+
+// addResult records a finished check
+// A check stopped with the crawl is not finished, so it is left out
+func (c *crawler) addResult(result LinkResult) {
+	if errors.Is(result.Error, errStopped) {
+		return
+	}
+	c.resultsMu.Lock()
+	c.results = append(c.results, result)
+	c.resultsMu.Unlock()
+
+	c.opts.emit(result)
+}
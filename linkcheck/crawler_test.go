@@ -0,0 +1,891 @@
+package linkcheck
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCrawl_SinglePage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, `<html><body><h1>Test Page</h1></body></html>`)
+	}))
+	defer server.Close()
+
+	fetcher := &HTTPFetcher{Client: &http.Client{Timeout: 5 * time.Second}}
+	results := crawl(t.Context(), fetcher, server.URL, newCrawlBudget(crawlOptions{MaxDepth: DefaultMaxDepth}), checkOptions{Concurrency: DefaultConcurrency})
+
+	if len(results) != 1 {
+		t.Errorf("Expected 1 result, got %d", len(results))
+	}
+
+	if results[0].Status != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", results[0].Status)
+	}
+
+	if results[0].Broken() {
+		t.Error("Expected link not to be broken")
+	}
+}
+
+func TestCrawl_WithInternalLinks(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, `<html><body>
+			<a href="/page1">Page 1</a>
+			<a href="/page2">Page 2</a>
+		</body></html>`)
+	})
+	mux.HandleFunc("/page1", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, `<html><body><h1>Page 1</h1></body></html>`)
+	})
+	mux.HandleFunc("/page2", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, `<html><body><h1>Page 2</h1></body></html>`)
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	fetcher := &HTTPFetcher{Client: &http.Client{Timeout: 5 * time.Second}}
+	results := crawl(t.Context(), fetcher, server.URL, newCrawlBudget(crawlOptions{MaxDepth: DefaultMaxDepth}), checkOptions{Concurrency: DefaultConcurrency})
+
+	// Should crawl: root, page1, page2 = 3 pages
+	if len(results) < 3 {
+		t.Errorf("Expected at least 3 results, got %d", len(results))
+	}
+}
+
+func TestCrawl_MaxDepth(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		// Always link to /next to create infinite depth
+		fmt.Fprint(w, `<html><body><a href="/next">Next</a></body></html>`)
+	}))
+	defer server.Close()
+
+	fetcher := &HTTPFetcher{Client: &http.Client{Timeout: 5 * time.Second}}
+	results := crawl(t.Context(), fetcher, server.URL, newCrawlBudget(crawlOptions{MaxDepth: DefaultMaxDepth}), checkOptions{Concurrency: DefaultConcurrency})
+
+	// Should respect maxDepth and not crawl infinitely
+	// At depth 0, 1, 2 we crawl. At depth 3+ we stop.
+	if len(results) > 10 {
+		t.Errorf("Crawl depth not respected, got %d results (expected limited by maxDepth)", len(results))
+	}
+}
+
+func TestCrawl_ExternalLinks(t *testing.T) {
+	var externalMethod string
+	externalServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		externalMethod = r.Method
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, `<html><body><h1>External</h1></body></html>`)
+	}))
+	defer externalServer.Close()
+
+	mainServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, `<html><body>
+			<a href="%s">External Link</a>
+		</body></html>`, externalServer.URL)
+	}))
+	defer mainServer.Close()
+
+	fetcher := &HTTPFetcher{Client: &http.Client{Timeout: 5 * time.Second}}
+	results := crawl(t.Context(), fetcher, mainServer.URL, newCrawlBudget(crawlOptions{MaxDepth: DefaultMaxDepth}), checkOptions{Concurrency: DefaultConcurrency})
+
+	// Should check both the main page and the external link
+	if len(results) < 2 {
+		t.Errorf("Expected at least 2 results (main + external), got %d", len(results))
+	}
+
+	// Verify external link was checked but not crawled deeply
+	foundExternal := false
+	for _, result := range results {
+		if result.URL == externalServer.URL {
+			foundExternal = true
+			if result.Status != http.StatusOK {
+				t.Errorf("External link status = %d, want 200", result.Status)
+			}
+		}
+	}
+
+	if !foundExternal {
+		t.Error("External link was not checked")
+	}
+
+	// external pages are never parsed, so their body is not downloaded
+	if externalMethod != http.MethodHead {
+		t.Errorf("External link checked with %s, want HEAD", externalMethod)
+	}
+}
+
+func TestCrawl_BrokenLinks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/broken" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, `<html><body>
+			<a href="/broken">Broken Link</a>
+		</body></html>`)
+	}))
+	defer server.Close()
+
+	fetcher := &HTTPFetcher{Client: &http.Client{Timeout: 5 * time.Second}}
+	results := crawl(t.Context(), fetcher, server.URL, newCrawlBudget(crawlOptions{MaxDepth: DefaultMaxDepth}), checkOptions{Concurrency: DefaultConcurrency})
+
+	// Find the broken link result
+	var brokenResult *LinkResult
+	for i := range results {
+		if results[i].URL == server.URL+"/broken" {
+			brokenResult = &results[i]
+			break
+		}
+	}
+
+	if brokenResult == nil {
+		t.Fatal("Broken link not found in results")
+	}
+
+	if !brokenResult.Broken() {
+		t.Error("Expected broken link to be marked as broken")
+	}
+
+	if brokenResult.Status != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", brokenResult.Status)
+	}
+}
+
+func TestCrawl_DuplicateVisitPrevention(t *testing.T) {
+	visitedURLs := make(map[string]int)
+	var mu sync.Mutex
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		visitedURLs[r.URL.Path]++
+		mu.Unlock()
+
+		w.WriteHeader(http.StatusOK)
+		// Create a circular reference
+		fmt.Fprint(w, `<html><body><a href="/page1">Page</a></body></html>`)
+	}))
+	defer server.Close()
+
+	fetcher := &HTTPFetcher{Client: &http.Client{Timeout: 5 * time.Second}}
+	crawl(t.Context(), fetcher, server.URL, newCrawlBudget(crawlOptions{MaxDepth: DefaultMaxDepth}), checkOptions{Concurrency: DefaultConcurrency})
+
+	mu.Lock()
+	totalVisits := 0
+	for _, count := range visitedURLs {
+		totalVisits += count
+	}
+	mu.Unlock()
+
+	// Should visit each unique path only once
+	// With root, /page1 and the cached /robots.txt, that's 3 unique URLs
+	if totalVisits > 3 {
+		t.Errorf("Expected at most 3 unique URL visits, got %d total visits: %v", totalVisits, visitedURLs)
+	}
+
+	// Verify no URL was visited more than once
+	mu.Lock()
+	for path, count := range visitedURLs {
+		if count > 1 {
+			t.Errorf("Path %s was visited %d times, expected 1", path, count)
+		}
+	}
+	mu.Unlock()
+}
+
+func TestCrawl_DepthOption(t *testing.T) {
+	tests := []struct {
+		name      string
+		maxDepth  int
+		wantPages int
+	}{
+		{name: "depth 0 checks only the start page", maxDepth: 0, wantPages: 1},
+		{name: "depth 1 follows one level", maxDepth: 1, wantPages: 2},
+		{name: "depth 4 follows four levels", maxDepth: 4, wantPages: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// every page links one level deeper: / -> /1 -> /1/1 -> ...
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				next := strings.TrimSuffix(r.URL.Path, "/") + "/1"
+				fmt.Fprintf(w, `<html><body><a href="%s">Next</a></body></html>`, next)
+			}))
+			defer server.Close()
+
+			fetcher := &HTTPFetcher{Client: &http.Client{Timeout: 5 * time.Second}}
+			budget := newCrawlBudget(crawlOptions{MaxDepth: tt.maxDepth})
+			results := crawl(t.Context(), fetcher, server.URL, budget, checkOptions{Concurrency: DefaultConcurrency})
+
+			if len(results) != tt.wantPages {
+				t.Errorf("Expected %d results, got %d", tt.wantPages, len(results))
+			}
+
+			if budget.Truncated() {
+				t.Error("Depth limit should not mark the crawl as truncated")
+			}
+		})
+	}
+}
+
+func TestCrawl_MaxPages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, `<html><body>
+			<a href="/a">A</a>
+			<a href="/b">B</a>
+			<a href="/c">C</a>
+			<a href="/d">D</a>
+		</body></html>`)
+	}))
+	defer server.Close()
+
+	fetcher := &HTTPFetcher{Client: &http.Client{Timeout: 5 * time.Second}}
+	budget := newCrawlBudget(crawlOptions{MaxDepth: DefaultMaxDepth, MaxPages: 3})
+	results := crawl(t.Context(), fetcher, server.URL, budget, checkOptions{Concurrency: DefaultConcurrency})
+
+	if len(results) != 3 {
+		t.Errorf("Expected 3 results with -max-pages 3, got %d", len(results))
+	}
+
+	if !budget.Truncated() {
+		t.Error("Expected crawl to be marked as truncated")
+	}
+}
+
+func TestCrawl_MaxExternal(t *testing.T) {
+	externalServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer externalServer.Close()
+
+	mainServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, `<html><body>
+			<a href="%[1]s/one">One</a>
+			<a href="%[1]s/two">Two</a>
+			<a href="%[1]s/three">Three</a>
+		</body></html>`, externalServer.URL)
+	}))
+	defer mainServer.Close()
+
+	fetcher := &HTTPFetcher{Client: &http.Client{Timeout: 5 * time.Second}}
+	budget := newCrawlBudget(crawlOptions{MaxDepth: DefaultMaxDepth, MaxExternal: 1})
+	results := crawl(t.Context(), fetcher, mainServer.URL, budget, checkOptions{Concurrency: DefaultConcurrency})
+
+	// main page + one external link
+	if len(results) != 2 {
+		t.Errorf("Expected 2 results with -max-external 1, got %d", len(results))
+	}
+
+	if !budget.Truncated() {
+		t.Error("Expected crawl to be marked as truncated")
+	}
+}
+
+func TestCrawl_ConcurrencyLimit(t *testing.T) {
+	const concurrency = 3
+	var inFlight, maxInFlight int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			m := atomic.LoadInt32(&maxInFlight)
+			if n <= m || atomic.CompareAndSwapInt32(&maxInFlight, m, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		w.WriteHeader(http.StatusOK)
+		if r.URL.Path == "/" {
+			for i := range 20 {
+				fmt.Fprintf(w, `<a href="/page%d">Page</a>`, i)
+			}
+		}
+	}))
+	defer server.Close()
+
+	fetcher := &HTTPFetcher{Client: &http.Client{Timeout: 5 * time.Second}}
+	results := crawl(t.Context(), fetcher, server.URL, newCrawlBudget(crawlOptions{MaxDepth: DefaultMaxDepth}), checkOptions{Concurrency: concurrency})
+
+	if len(results) != 21 {
+		t.Errorf("Expected 21 results, got %d", len(results))
+	}
+
+	if got := atomic.LoadInt32(&maxInFlight); got > concurrency {
+		t.Errorf("Expected at most %d concurrent requests, got %d", concurrency, got)
+	}
+}
+
+func TestCrawl_Robots(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/robots.txt":
+			fmt.Fprint(w, "User-agent: *\nDisallow: /private\n")
+		case "/private/page":
+			t.Error("Disallowed page was fetched")
+		default:
+			fmt.Fprint(w, `<html><body>
+				<a href="/public">Public</a>
+				<a href="/private/page">Private</a>
+			</body></html>`)
+		}
+	}))
+	defer server.Close()
+
+	fetcher := &HTTPFetcher{Client: &http.Client{Timeout: 5 * time.Second}}
+	results := crawl(t.Context(), fetcher, server.URL, newCrawlBudget(crawlOptions{MaxDepth: 1}), checkOptions{Concurrency: DefaultConcurrency})
+
+	var skipped *LinkResult
+	for i := range results {
+		if results[i].URL == server.URL+"/private/page" {
+			skipped = &results[i]
+		}
+	}
+
+	if skipped == nil {
+		t.Fatal("Disallowed page missing from results")
+	}
+	if skipped.Skipped != SkipRobots {
+		t.Errorf("Skipped = %q, want %q", skipped.Skipped, SkipRobots)
+	}
+	if skipped.Broken() {
+		t.Error("Skipped page should not be broken")
+	}
+	if len(results) != 3 {
+		t.Errorf("Expected 3 results, got %d", len(results))
+	}
+}
+
+func TestCrawl_UnreachableRobots(t *testing.T) {
+	fetcher := ReplayFetcher{
+		"https://example.com/robots.txt": {Status: http.StatusServiceUnavailable},
+		"https://example.com":            {Body: `<a href="/page">Page</a>`},
+	}
+	results := crawl(t.Context(), fetcher, "https://example.com", newCrawlBudget(crawlOptions{MaxDepth: 1}), checkOptions{Concurrency: DefaultConcurrency})
+
+	// the start page is not fetched, and fails instead of being skipped
+	if len(results) != 1 || !results[0].Broken() || results[0].Status != 0 {
+		t.Errorf("Expected the start page failed by robots.txt, got %+v", results)
+	}
+}
+
+func TestCrawl_IgnoreRobots(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/robots.txt":
+			t.Error("robots.txt fetched with IgnoreRobots")
+		case "/":
+			fmt.Fprint(w, `<a href="/private">Private</a>`)
+		}
+	}))
+	defer server.Close()
+
+	fetcher := &HTTPFetcher{Client: &http.Client{Timeout: 5 * time.Second}}
+	opts := crawlOptions{MaxDepth: 1, IgnoreRobots: true}
+	results := crawl(t.Context(), fetcher, server.URL, newCrawlBudget(opts), checkOptions{Concurrency: DefaultConcurrency})
+
+	for _, result := range results {
+		if result.Skipped != "" {
+			t.Errorf("Unexpected skipped result for %s", result.URL)
+		}
+	}
+}
+
+func TestCrawl_Redirect(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<a href="/old/">Old docs</a>`)
+	})
+	mux.HandleFunc("/old/", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/docs/", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/docs/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/docs/" {
+			fmt.Fprint(w, `<a href="page">Page</a>`)
+		}
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	fetcher := &HTTPFetcher{Client: &http.Client{Timeout: 5 * time.Second}}
+	results := crawl(t.Context(), fetcher, server.URL, newCrawlBudget(crawlOptions{MaxDepth: DefaultMaxDepth}), checkOptions{Concurrency: DefaultConcurrency})
+
+	byURL := make(map[string]LinkResult)
+	for _, result := range results {
+		byURL[result.URL] = result
+	}
+
+	old, ok := byURL[server.URL+"/old/"]
+	if !ok {
+		t.Fatal("Redirected page missing from results")
+	}
+	if old.Warning == "" || len(old.Redirects) != 1 {
+		t.Errorf("Expected permanent redirect warning and 1 hop, got %q and %d hops", old.Warning, len(old.Redirects))
+	}
+
+	// relative links resolve against the page after the redirect
+	if _, ok := byURL[server.URL+"/docs/page"]; !ok {
+		t.Errorf("Link on redirected page not resolved against final URL, results: %v", results)
+	}
+}
+
+func TestCrawl_Fragments(t *testing.T) {
+	var mu sync.Mutex
+	fetches := make(map[string]int)
+
+	externalServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<h2 id="external-section">External</h2>`)
+	}))
+	defer externalServer.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		fetches[r.URL.Path]++
+		mu.Unlock()
+
+		switch r.URL.Path {
+		case "/":
+			fmt.Fprintf(w, `<html><body>
+				<h1 id="top-section">Home</h1>
+				<a href="#top-section">Same page, valid</a>
+				<a href="#nowhere">Same page, missing</a>
+				<a href="/guide#install">Guide, valid</a>
+				<a href="/guide#missing">Guide, missing</a>
+				<a href="/guide#install">Guide, duplicate</a>
+				<a href="%[1]s/#external-section">External, valid</a>
+				<a href="%[1]s/#gone">External, missing</a>
+			</body></html>`, externalServer.URL)
+		case "/guide":
+			fmt.Fprint(w, `<html><body><h2 id="install">Install</h2></body></html>`)
+		}
+	}))
+	defer server.Close()
+
+	fetcher := &HTTPFetcher{Client: &http.Client{Timeout: 5 * time.Second}}
+	results := crawl(t.Context(), fetcher, server.URL, newCrawlBudget(crawlOptions{MaxDepth: DefaultMaxDepth}), checkOptions{Concurrency: DefaultConcurrency})
+
+	broken := make(map[string]string)
+	for _, result := range results {
+		if result.Fragment != "" {
+			broken[result.URL] = result.Fragment
+		}
+	}
+
+	want := map[string]string{
+		server.URL + "#nowhere":       "nowhere",
+		server.URL + "/guide#missing": "missing",
+		externalServer.URL + "/#gone": "gone",
+	}
+	if len(broken) != len(want) {
+		t.Errorf("Broken fragments = %v, want %v", broken, want)
+	}
+	for link, fragment := range want {
+		if broken[link] != fragment {
+			t.Errorf("Expected broken fragment %q for %s, got %q", fragment, link, broken[link])
+		}
+	}
+
+	// the guide page is fetched once for all three fragment links
+	mu.Lock()
+	defer mu.Unlock()
+	if fetches["/guide"] != 1 {
+		t.Errorf("/guide fetched %d times, want 1", fetches["/guide"])
+	}
+}
+
+func TestCrawl_Resources(t *testing.T) {
+	var mu sync.Mutex
+	requests := make(map[string]string)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests[r.URL.Path] = r.Method
+		mu.Unlock()
+
+		switch r.URL.Path {
+		case "/":
+			fmt.Fprint(w, `<html><head>
+				<link rel="stylesheet" href="/style.css">
+				<script src="/missing.js"></script>
+			</head><body>
+				<img src="/logo.png">
+				<a href="/page">Page</a>
+			</body></html>`)
+		case "/page":
+			fmt.Fprint(w, `<html><body><img src="/broken.png"></body></html>`)
+		case "/style.css", "/logo.png":
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name       string
+		check      []string
+		wantBroken map[string]string // broken URL path -> element
+	}{
+		{
+			name:       "all kinds",
+			wantBroken: map[string]string{"/missing.js": "script", "/broken.png": "img"},
+		},
+		{
+			name:       "images only",
+			check:      []string{KindImages},
+			wantBroken: map[string]string{"/broken.png": "img"},
+		},
+		{
+			name:       "scripts only",
+			check:      []string{KindScripts},
+			wantBroken: map[string]string{"/missing.js": "script"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetcher := &HTTPFetcher{Client: &http.Client{Timeout: 5 * time.Second}}
+			budget := newCrawlBudget(crawlOptions{MaxDepth: DefaultMaxDepth, Check: tt.check})
+			results := crawl(t.Context(), fetcher, server.URL, budget, checkOptions{Concurrency: DefaultConcurrency})
+
+			broken := make(map[string]string)
+			for _, result := range results {
+				if result.Broken() {
+					broken[strings.TrimPrefix(result.URL, server.URL)] = result.Element
+				}
+			}
+			if len(broken) != len(tt.wantBroken) {
+				t.Errorf("Broken = %v, want %v", broken, tt.wantBroken)
+			}
+			for path, element := range tt.wantBroken {
+				if broken[path] != element {
+					t.Errorf("Expected %s to be broken in <%s>, got %v", path, element, broken)
+				}
+			}
+		})
+	}
+
+	// resources are checked, never crawled as pages
+	mu.Lock()
+	defer mu.Unlock()
+	if requests["/logo.png"] != http.MethodHead {
+		t.Errorf("/logo.png requested with %s, want HEAD", requests["/logo.png"])
+	}
+}
+
+func TestCrawl_Locations(t *testing.T) {
+	var externalRequests int32
+	externalServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&externalRequests, 1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer externalServer.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			fmt.Fprintf(w, `<a href="/one">One</a><a href="/two">Two</a><a href="%s/gone">Gone</a>`, externalServer.URL)
+		case "/one", "/two":
+			fmt.Fprintf(w, "<p>Broken:</p>\n<a href=\"%s/gone\">Gone</a>", externalServer.URL)
+		}
+	}))
+	defer server.Close()
+
+	fetcher := &HTTPFetcher{Client: &http.Client{Timeout: 5 * time.Second}}
+	results := crawl(t.Context(), fetcher, server.URL, newCrawlBudget(crawlOptions{MaxDepth: DefaultMaxDepth}), checkOptions{Concurrency: DefaultConcurrency})
+
+	var gone []LinkResult
+	for _, result := range results {
+		if result.URL == externalServer.URL+"/gone" {
+			gone = append(gone, result)
+		}
+	}
+
+	if len(gone) != 1 {
+		t.Fatalf("Got %d results for the broken link, want 1", len(gone))
+	}
+	if externalRequests != 1 {
+		t.Errorf("Broken link fetched %d times, want 1", externalRequests)
+	}
+
+	wantSources := []string{server.URL, server.URL + "/one", server.URL + "/two"}
+	if len(gone[0].Locations) != len(wantSources) {
+		t.Fatalf("Locations = %v, want sources %v", gone[0].Locations, wantSources)
+	}
+	for i, source := range wantSources {
+		if gone[0].Locations[i].Source != source {
+			t.Errorf("Locations[%d].Source = %q, want %q", i, gone[0].Locations[i].Source, source)
+		}
+	}
+	if loc := gone[0].Locations[1]; loc.Line != 2 || loc.Element != "a" {
+		t.Errorf("Location on /one = %v, want line 2 in <a>", loc)
+	}
+}
+
+func TestCrawl_Exclude(t *testing.T) {
+	var mu sync.Mutex
+	requests := make(map[string]bool)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests[r.URL.Path] = true
+		mu.Unlock()
+
+		switch r.URL.Path {
+		case "/":
+			fmt.Fprint(w, `<html><body>
+				<a href="/docs/">Docs</a>
+				<a href="/private/">Private</a>
+				<img src="/private/logo.png">
+			</body></html>`)
+		case "/docs/":
+			fmt.Fprint(w, `<html><body><a href="/docs/page">Page</a></body></html>`)
+		case "/private/":
+			fmt.Fprint(w, `<html><body><a href="/private/secret">Secret</a></body></html>`)
+		}
+	}))
+	defer server.Close()
+
+	var rules URLRules
+	rules.Exclude.Set("*/private/*")
+	rules.Include.Set("*/private/logo.png")
+
+	fetcher := &HTTPFetcher{Client: &http.Client{Timeout: 5 * time.Second}}
+	budget := newCrawlBudget(crawlOptions{MaxDepth: DefaultMaxDepth})
+	results := crawl(t.Context(), fetcher, server.URL, budget, checkOptions{Concurrency: DefaultConcurrency, Rules: rules})
+
+	excluded := make(map[string]string)
+	for _, result := range results {
+		if result.Skipped == SkipExcluded {
+			excluded[strings.TrimPrefix(result.URL, server.URL)] = result.Rule
+		}
+	}
+
+	if len(excluded) != 1 || excluded["/private/"] != "exclude */private/*" {
+		t.Errorf("excluded = %v, want only /private/ by exclude */private/*", excluded)
+	}
+	if requests["/private/"] || requests["/private/secret"] {
+		t.Error("excluded page was fetched or followed")
+	}
+	if !requests["/private/logo.png"] || !requests["/docs/page"] {
+		t.Errorf("requests = %v, want the included image and the docs page checked", requests)
+	}
+}
+
+func TestCrawl_Suppressed(t *testing.T) {
+	var mu sync.Mutex
+	requests := make(map[string]bool)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests[r.URL.Path] = true
+		mu.Unlock()
+
+		switch r.URL.Path {
+		case "/":
+			fmt.Fprint(w, "<html><body>\n"+
+				"<!-- linkchecker-disable-next-line -->\n"+
+				"<a href=\"/known-bad\">Known bad</a>\n"+
+				"<a href=\"/page\">Page</a>\n"+
+				"</body></html>")
+		case "/page":
+			fmt.Fprint(w, `<html><body><a href="/known-bad">Again</a></body></html>`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	fetcher := &HTTPFetcher{Client: &http.Client{Timeout: 5 * time.Second}}
+	budget := newCrawlBudget(crawlOptions{MaxDepth: DefaultMaxDepth})
+	results := crawl(t.Context(), fetcher, server.URL, budget, checkOptions{Concurrency: DefaultConcurrency})
+
+	var suppressed, broken []LinkResult
+	for _, result := range results {
+		switch {
+		case result.Skipped == SkipSuppressed:
+			suppressed = append(suppressed, result)
+		case result.Broken():
+			broken = append(broken, result)
+		}
+	}
+
+	// the suppressed occurrence is reported on its own, the other one is checked
+	if len(suppressed) != 1 || suppressed[0].Line != 3 || len(suppressed[0].Locations) != 1 {
+		t.Errorf("suppressed = %+v, want the occurrence on line 3 only", suppressed)
+	}
+	if len(broken) != 1 || broken[0].SourceURL != server.URL+"/page" || len(broken[0].Locations) != 1 {
+		t.Errorf("broken = %+v, want /known-bad found on /page", broken)
+	}
+}
+
+func BenchmarkCrawl(b *testing.B) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, `<html><body>
+			<a href="/page1">Page 1</a>
+			<a href="/page2">Page 2</a>
+		</body></html>`)
+	}))
+	defer server.Close()
+
+	fetcher := &HTTPFetcher{Client: &http.Client{Timeout: 5 * time.Second}}
+
+	b.ResetTimer()
+	for b.Loop() {
+		crawl(b.Context(), fetcher, server.URL, newCrawlBudget(crawlOptions{MaxDepth: DefaultMaxDepth}), checkOptions{Concurrency: DefaultConcurrency})
+	}
+}
+
+func TestCrawl_Replay(t *testing.T) {
+	// an in-memory site: no server, every response comes from the fake
+	fetcher := ReplayFetcher{
+		"https://example.com":          {Body: `<a href="/docs/">Docs</a><a href="https://other.example/">Other</a>`},
+		"https://example.com/docs/":    {Status: http.StatusMovedPermanently, Header: http.Header{"Location": {"/docs/v2/"}}},
+		"https://example.com/docs/v2/": {Body: `<a href="/gone">Gone</a>`},
+		"https://example.com/gone":     {Status: http.StatusNotFound},
+		"https://other.example/":       {},
+	}
+	budget := newCrawlBudget(crawlOptions{MaxDepth: 3, IgnoreRobots: true})
+	results := crawl(t.Context(), fetcher, "https://example.com", budget, checkOptions{Concurrency: DefaultConcurrency})
+
+	byURL := make(map[string]LinkResult)
+	for _, result := range results {
+		byURL[result.URL] = result
+	}
+	if len(byURL) != 4 {
+		t.Fatalf("Expected 4 results, got %+v", results)
+	}
+	if got := byURL["https://example.com/docs/"]; got.Status != http.StatusOK || len(got.Redirects) != 1 {
+		t.Errorf("docs = %+v, want the redirect followed", got)
+	}
+	if got := byURL["https://example.com/gone"]; !got.Broken() || got.SourceURL != "https://example.com/docs/" {
+		t.Errorf("gone = %+v, want broken and found on the docs page", got)
+	}
+	if got := byURL["https://other.example/"]; got.Broken() {
+		t.Errorf("other = %+v, want the external link checked", got)
+	}
+}
+
+func TestCrawl_ContentType(t *testing.T) {
+	// only pages with a registered Content-Type are parsed for links, whatever
+	// their extension; the links inside the PDF and the zip are never followed
+	fetcher := ReplayFetcher{
+		"https://example.com": {Body: `<a href="/manual.pdf">Manual</a><a href="/notes">Notes</a><a href="/site.html">Site</a>`},
+		"https://example.com/manual.pdf": {
+			Header: http.Header{"Content-Type": {"application/pdf"}},
+			Body:   `<a href="/from-pdf">PDF</a>`,
+		},
+		"https://example.com/site.html": {
+			Header: http.Header{"Content-Type": {"application/zip"}},
+			Body:   `<a href="/from-zip">Zip</a>`,
+		},
+		"https://example.com/notes": {
+			Header: http.Header{"Content-Type": {"text/markdown; charset=utf-8"}},
+			Body:   "See the [guide](guide).\n",
+		},
+		"https://example.com/guide": {},
+	}
+	budget := newCrawlBudget(crawlOptions{MaxDepth: 3, IgnoreRobots: true})
+	results := crawl(t.Context(), fetcher, "https://example.com", budget, checkOptions{Concurrency: DefaultConcurrency})
+
+	byURL := make(map[string]LinkResult)
+	for _, result := range results {
+		byURL[result.URL] = result
+	}
+	if len(byURL) != 5 {
+		t.Fatalf("Expected 5 results, got %+v", results)
+	}
+	if got, ok := byURL["https://example.com/guide"]; !ok || got.SourceURL != "https://example.com/notes" {
+		t.Errorf("guide = %+v, want found on the Markdown page", got)
+	}
+}
+
+func TestCrawl_PageFirstReachedByResourceLink(t *testing.T) {
+	// /p2 is first seen through <link rel=next>, which only checks it, and
+	// is still crawled once the <a> link reaches it
+	fetcher := ReplayFetcher{
+		"https://example.com":    {Body: `<link rel="next" href="/p2"><a href="/p2">Next</a>`},
+		"https://example.com/p2": {Body: `<a href="/p3">Next</a>`},
+		"https://example.com/p3": {},
+	}
+	budget := newCrawlBudget(crawlOptions{MaxDepth: 3, IgnoreRobots: true})
+	results := crawl(t.Context(), fetcher, "https://example.com", budget, checkOptions{Concurrency: 1})
+
+	count := make(map[string]int)
+	for _, result := range results {
+		count[result.URL]++
+	}
+	if count["https://example.com/p3"] != 1 {
+		t.Errorf("Expected /p3 found by crawling /p2, got %+v", results)
+	}
+	if count["https://example.com/p2"] != 1 {
+		t.Errorf("Expected /p2 reported once, got %d results", count["https://example.com/p2"])
+	}
+}
+
+func TestCrawl_Markdown(t *testing.T) {
+	// links of Markdown pages are followed like <a> links, images are only checked
+	markdown := http.Header{"Content-Type": {"text/markdown"}}
+	fetcher := ReplayFetcher{
+		"https://example.com":           {Body: `<a href="/README.md">Read me</a>`},
+		"https://example.com/README.md": {Header: markdown, Body: "[Guide](guide.md)\n\n![logo](logo.png)\n"},
+		"https://example.com/guide.md":  {Header: markdown, Body: "[Deep](/deep)\n"},
+		"https://example.com/logo.png":  {Header: http.Header{"Content-Type": {"image/png"}}},
+		"https://example.com/deep":      {},
+	}
+	budget := newCrawlBudget(crawlOptions{MaxDepth: 3, IgnoreRobots: true})
+	results := crawl(t.Context(), fetcher, "https://example.com", budget, checkOptions{Concurrency: DefaultConcurrency})
+
+	byURL := make(map[string]LinkResult)
+	for _, result := range results {
+		byURL[result.URL] = result
+	}
+	if len(byURL) != 5 {
+		t.Fatalf("Expected 5 results, got %+v", results)
+	}
+	if got := byURL["https://example.com/deep"]; got.SourceURL != "https://example.com/guide.md" {
+		t.Errorf("deep = %+v, want found by crawling the Markdown guide", got)
+	}
+}
+
+func TestCrawl_MarkdownMailto(t *testing.T) {
+	// mailto: and tel: links of Markdown pages are neither checked nor followed
+	fetcher := ReplayFetcher{
+		"https://example.com": {
+			Header: http.Header{"Content-Type": {"text/markdown"}},
+			Body:   "[me](mailto:a@example.com) [call](tel:+1234) [Guide](/guide)\n",
+		},
+		"https://example.com/guide": {},
+	}
+	budget := newCrawlBudget(crawlOptions{MaxDepth: 3, IgnoreRobots: true})
+	results := crawl(t.Context(), fetcher, "https://example.com", budget, checkOptions{Concurrency: DefaultConcurrency})
+
+	if len(results) != 2 {
+		t.Fatalf("Expected the page and /guide only, got %+v", results)
+	}
+	for _, result := range results {
+		if result.Broken() {
+			t.Errorf("%s reported broken: %+v", result.URL, result)
+		}
+	}
+}
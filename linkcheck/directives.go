@@ -0,0 +1,80 @@
+// directives.go - Inline linkchecker comments that suppress links
+
+package linkcheck
+
+import "strings"
+
+// inline directives, written as HTML comments in HTML and Markdown documents
+const (
+	directiveDisableNextLine = "linkchecker-disable-next-line" // suppress links on the following line
+	directiveDisable         = "linkchecker-disable"           // suppress links until linkchecker-enable
+	directiveEnable          = "linkchecker-enable"            // end a linkchecker-disable block
+	ignoreAttr               = "data-linkchecker-ignore"       // suppress the links of an HTML element and its children
+)
+
+// suppressor tracks the directives of a document read from start to end
+type suppressor struct {
+	disabled bool // inside a linkchecker-disable block
+	nextLine int  // line suppressed by linkchecker-disable-next-line, 0 if none
+}
+
+// comment applies an HTML comment that ends on endLine if it is a directive
+// Text after the directive, such as the reason a link is suppressed, is ignored
+func (s *suppressor) comment(text string, endLine int) {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return
+	}
+
+	switch fields[0] {
+	case directiveDisableNextLine:
+		s.nextLine = endLine + 1
+	case directiveDisable:
+		s.disabled = true
+	case directiveEnable:
+		s.disabled = false
+	}
+}
+
+// suppresses reports whether a link on a line is suppressed
+func (s *suppressor) suppresses(line int) bool {
+	return s.disabled || line == s.nextLine
+}
+
+// ignoredElement tracks the HTML element carrying data-linkchecker-ignore
+// whose children are being read
+type ignoredElement struct {
+	tag   string
+	depth int // open elements named tag, 0 when outside an ignored element
+}
+
+// start records a start tag and reports whether its links are suppressed
+func (e *ignoredElement) start(tag string, ignored, selfClosing bool) bool {
+	if e.depth > 0 {
+		if tag == e.tag && !selfClosing && !isVoidElement(tag) {
+			e.depth++
+		}
+		return true
+	}
+
+	if ignored && !selfClosing && !isVoidElement(tag) {
+		e.tag, e.depth = tag, 1
+	}
+	return ignored
+}
+
+// end records an end tag
+func (e *ignoredElement) end(tag string) {
+	if e.depth > 0 && tag == e.tag {
+		e.depth--
+	}
+}
+
+// isVoidElement reports whether an HTML element has no end tag or children
+func isVoidElement(tag string) bool {
+	switch tag {
+	case "area", "base", "br", "col", "embed", "hr", "img", "input", "link", "meta", "source", "track", "wbr":
+		return true
+	}
+	return false
+}
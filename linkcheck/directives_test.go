@@ -0,0 +1,61 @@
+package linkcheck
+
+import "testing"
+
+func TestSuppressor(t *testing.T) {
+	var s suppressor
+
+	s.comment(" just a comment ", 1)
+	if s.suppresses(2) {
+		t.Error("plain comment suppresses links")
+	}
+
+	s.comment(" linkchecker-disable-next-line flaky host ", 3)
+	if s.suppresses(3) || !s.suppresses(4) || s.suppresses(5) {
+		t.Error("linkchecker-disable-next-line should only suppress the following line")
+	}
+
+	s.comment("linkchecker-disable", 6)
+	if !s.suppresses(7) || !s.suppresses(100) {
+		t.Error("linkchecker-disable should suppress every line until linkchecker-enable")
+	}
+
+	s.comment("linkchecker-enable", 101)
+	if s.suppresses(102) {
+		t.Error("linkchecker-enable should end the disabled block")
+	}
+}
+
+func TestIgnoredElement(t *testing.T) {
+	var e ignoredElement
+
+	steps := []struct {
+		tag         string
+		end         bool
+		ignored     bool
+		selfClosing bool
+		want        bool
+	}{
+		{tag: "img", ignored: true, want: true},
+		{tag: "a", want: false},
+		{tag: "a", end: true},
+		{tag: "div", ignored: true, want: true},
+		{tag: "div", want: true},
+		{tag: "a", want: true},
+		{tag: "a", end: true},
+		{tag: "div", end: true},
+		{tag: "img", want: true},
+		{tag: "div", end: true},
+		{tag: "a", want: false},
+	}
+
+	for i, step := range steps {
+		if step.end {
+			e.end(step.tag)
+			continue
+		}
+		if got := e.start(step.tag, step.ignored, step.selfClosing); got != step.want {
+			t.Errorf("step %d: start(%q) = %v, want %v", i, step.tag, got, step.want)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+// errorkind.go - Classifying failed requests by cause
+
+package linkcheck
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"net"
+	"net/url"
+	"strings"
+	"syscall"
+)
+
+// ErrorKind is the stable, machine-readable cause of a failed request
+type ErrorKind string
+
+const (
+	ErrorDNSNotFound       ErrorKind = "dns_not_found"      // the host name could not be resolved
+	ErrorConnectionRefused ErrorKind = "connection_refused" // nothing listens on the host and port
+	ErrorTimeout           ErrorKind = "timeout"            // no response within the request timeout
+	ErrorTLSCertificate    ErrorKind = "tls_certificate"    // the certificate is expired, self-signed or for another host
+	ErrorTLSHandshake      ErrorKind = "tls_handshake"      // TLS could not be negotiated
+	ErrorTooManyRedirects  ErrorKind = "too_many_redirects" // more than the maximum redirects, or a redirect loop
+	ErrorReset             ErrorKind = "reset"              // the server closed the connection without a response
+	ErrorInvalidURL        ErrorKind = "invalid_url"        // the URL or a redirect Location cannot be requested
+	ErrorOther             ErrorKind = "other"              // any other failure
+)
+
+// errorKindOf classifies the error of a failed request
+// The checks run from the most to the least specific, so a timeout while
+// resolving a host is a timeout rather than a DNS failure
+func errorKindOf(err error) ErrorKind {
+	var (
+		urlErr     *url.Error
+		netErr     net.Error
+		dnsErr     *net.DNSError
+		certErr    *tls.CertificateVerificationError
+		hostErr    x509.HostnameError
+		authErr    x509.UnknownAuthorityError
+		invalidErr x509.CertificateInvalidError
+		recordErr  tls.RecordHeaderError
+		alertErr   tls.AlertError
+	)
+
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, errTooManyRedirects), errors.Is(err, errRedirectLoop):
+		return ErrorTooManyRedirects
+	case errors.As(err, &urlErr) && urlErr.Op == "parse":
+		return ErrorInvalidURL
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return ErrorTimeout
+	case errors.As(err, &dnsErr):
+		return ErrorDNSNotFound
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return ErrorConnectionRefused
+	case errors.As(err, &certErr), errors.As(err, &hostErr), errors.As(err, &authErr), errors.As(err, &invalidErr):
+		return ErrorTLSCertificate
+	case errors.As(err, &recordErr), errors.As(err, &alertErr):
+		return ErrorTLSHandshake
+	case errors.Is(err, syscall.ECONNRESET), errors.Is(err, syscall.EPIPE),
+		errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return ErrorReset
+	}
+
+	// the standard library reports these without a type to match
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "unsupported protocol scheme"), strings.Contains(msg, "no Host in request URL"):
+		return ErrorInvalidURL
+	case strings.Contains(msg, "tls: "), strings.Contains(msg, "server gave HTTP response to HTTPS client"):
+		return ErrorTLSHandshake
+	}
+	return ErrorOther
+}
@@ -0,0 +1,118 @@
+package linkcheck
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestErrorKindOf(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want ErrorKind
+	}{
+		{name: "no error", err: nil, want: ""},
+		{name: "dns", err: &url.Error{Op: "Get", URL: "https://x.invalid", Err: &net.OpError{Op: "dial", Err: &net.DNSError{Err: "no such host", Name: "x.invalid", IsNotFound: true}}}, want: ErrorDNSNotFound},
+		{name: "dns timeout", err: &net.DNSError{Err: "i/o timeout", Name: "x.invalid", IsTimeout: true}, want: ErrorTimeout},
+		{name: "refused", err: &net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}, want: ErrorConnectionRefused},
+		{name: "deadline", err: fmt.Errorf("request: %w", context.DeadlineExceeded), want: ErrorTimeout},
+		{name: "unknown authority", err: &tls.CertificateVerificationError{Err: x509.UnknownAuthorityError{}}, want: ErrorTLSCertificate},
+		{name: "hostname", err: x509.HostnameError{Host: "example.com", Certificate: &x509.Certificate{}}, want: ErrorTLSCertificate},
+		{name: "alert", err: &net.OpError{Op: "remote error", Err: tls.AlertError(40)}, want: ErrorTLSHandshake},
+		{name: "tls message", err: errors.New("tls: handshake failure"), want: ErrorTLSHandshake},
+		{name: "too many redirects", err: fmt.Errorf("%w: stopped after 10 redirects", errTooManyRedirects), want: ErrorTooManyRedirects},
+		{name: "redirect loop", err: fmt.Errorf("%w: a redirects back to b", errRedirectLoop), want: ErrorTooManyRedirects},
+		{name: "reset", err: &net.OpError{Op: "read", Err: os.NewSyscallError("read", syscall.ECONNRESET)}, want: ErrorReset},
+		{name: "eof", err: &url.Error{Op: "Head", URL: "https://example.com", Err: io.EOF}, want: ErrorReset},
+		{name: "parse", err: &url.Error{Op: "parse", URL: "://x", Err: errors.New("missing protocol scheme")}, want: ErrorInvalidURL},
+		{name: "scheme", err: errors.New(`Get "ftp://x": unsupported protocol scheme "ftp"`), want: ErrorInvalidURL},
+		{name: "other", err: errors.New("something else"), want: ErrorOther},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := errorKindOf(tt.err); got != tt.want {
+				t.Errorf("errorKindOf(%v) = %q, want %q", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckURL_ErrorKinds(t *testing.T) {
+	// a port nothing listens on
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closedURL := "http://" + listener.Addr().String()
+	listener.Close()
+
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(300 * time.Millisecond)
+	}))
+	defer slow.Close()
+
+	// the client rejecting the certificate is not worth logging
+	selfSigned := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	selfSigned.Config.ErrorLog = log.New(io.Discard, "", 0)
+	selfSigned.StartTLS()
+	defer selfSigned.Close()
+
+	plain := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer plain.Close()
+
+	hangUp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err == nil {
+			conn.Close()
+		}
+	}))
+	defer hangUp.Close()
+
+	loop := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, r.URL.Path, http.StatusFound)
+	}))
+	defer loop.Close()
+
+	fetcher := &HTTPFetcher{Client: &http.Client{Timeout: 5 * time.Second}}
+	tests := []struct {
+		name    string
+		url     string
+		timeout time.Duration
+		want    ErrorKind
+	}{
+		{name: "connection refused", url: closedURL, want: ErrorConnectionRefused},
+		{name: "timeout", url: slow.URL, timeout: 50 * time.Millisecond, want: ErrorTimeout},
+		{name: "certificate", url: selfSigned.URL, want: ErrorTLSCertificate},
+		{name: "handshake", url: "https://" + plain.Listener.Addr().String(), want: ErrorTLSHandshake},
+		{name: "reset", url: hangUp.URL, want: ErrorReset},
+		{name: "redirect loop", url: loop.URL + "/a", want: ErrorTooManyRedirects},
+		{name: "invalid url", url: "://invalid-url", want: ErrorInvalidURL},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetcher := fetcher
+			if tt.timeout > 0 {
+				fetcher = &HTTPFetcher{Client: &http.Client{Timeout: tt.timeout}}
+			}
+			got := checkURL(t.Context(), fetcher, checkOptions{}, tt.url)
+			if got.ErrorKind != tt.want || !got.Broken() {
+				t.Errorf("ErrorKind = %q, want %q (error: %v)", got.ErrorKind, tt.want, got.Error)
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+// extractor.go - Link extractors registered by media type and file extension
+
+package linkcheck
+
+import (
+	"bufio"
+	"io"
+	"maps"
+	"mime"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Extractor finds the links of a document
+type Extractor interface {
+	// Extract returns the links of the document read from body, with relative
+	// URLs resolved against baseURL, or as written when baseURL is nil
+	Extract(body io.Reader, baseURL *url.URL) ([]Link, error)
+}
+
+// ExtractorFunc adapts a function to the Extractor interface
+type ExtractorFunc func(body io.Reader, baseURL *url.URL) ([]Link, error)
+
+// Extract calls f
+func (f ExtractorFunc) Extract(body io.Reader, baseURL *url.URL) ([]Link, error) {
+	return f(body, baseURL)
+}
+
+// HTMLExtractor extracts the links of HTML documents, see ExtractLinks
+type HTMLExtractor struct{}
+
+// Extract extracts the links of an HTML document
+func (HTMLExtractor) Extract(body io.Reader, baseURL *url.URL) ([]Link, error) {
+	return ExtractLinks(body, baseURL)
+}
+
+// MarkdownExtractor extracts the links of Markdown documents, see MarkdownLinks
+type MarkdownExtractor struct {
+	IncludeCode bool // also extract bare URLs from code spans and code blocks
+}
+
+// Extract extracts the links of a Markdown document
+func (e MarkdownExtractor) Extract(body io.Reader, baseURL *url.URL) ([]Link, error) {
+	content, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+
+	links := MarkdownLinks(string(content), MarkdownOptions{IncludeCode: e.IncludeCode})
+	for i := range links {
+		links[i].URL = resolveLink(baseURL, links[i].URL)
+	}
+	return links, nil
+}
+
+// URLListExtractor reads a list of URLs, one per line
+// Blank lines and lines starting with # are skipped
+type URLListExtractor struct{}
+
+// Extract returns every URL of the list with its position
+func (URLListExtractor) Extract(body io.Reader, baseURL *url.URL) ([]Link, error) {
+	var links []Link
+	scanner := bufio.NewScanner(body)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		text := scanner.Text()
+		line := strings.TrimSpace(text)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		links = append(links, Link{
+			URL:    resolveLink(baseURL, line),
+			Kind:   KindLinks,
+			Line:   lineNumber,
+			Column: len(text) - len(strings.TrimLeft(text, " \t")) + 1,
+		})
+	}
+	return links, scanner.Err()
+}
+
+// resolveLink resolves a link against baseURL, keeping it as written when
+// baseURL is nil or the link does not parse
+func resolveLink(baseURL *url.URL, link string) string {
+	if baseURL == nil {
+		return link
+	}
+	u, err := url.Parse(link)
+	if err != nil {
+		return link
+	}
+	return baseURL.ResolveReference(u).String()
+}
+
+// Extractors picks the Extractor of a document by its media type or file
+// extension. It is safe for concurrent use
+type Extractors struct {
+	byType map[string]Extractor
+	byExt  map[string]Extractor
+	mu     sync.RWMutex
+}
+
+// DefaultExtractors is used by a Checker created without WithExtractors
+var DefaultExtractors = NewExtractors()
+
+// NewExtractors creates a registry of the built-in extractors: HTML for
+// text/html, application/xhtml+xml, .html, .htm and .xhtml, Markdown for
+// text/markdown, text/x-markdown, .md and .markdown, and URL lists for .txt
+func NewExtractors() *Extractors {
+	r := &Extractors{
+		byType: make(map[string]Extractor),
+		byExt:  make(map[string]Extractor),
+	}
+	r.Register(HTMLExtractor{}, "text/html", "application/xhtml+xml", ".html", ".htm", ".xhtml")
+	r.Register(MarkdownExtractor{}, "text/markdown", "text/x-markdown", ".md", ".markdown")
+	r.Register(URLListExtractor{}, ".txt")
+	return r
+}
+
+// RegisterExtractor registers e in DefaultExtractors, see Extractors.Register
+func RegisterExtractor(e Extractor, keys ...string) {
+	DefaultExtractors.Register(e, keys...)
+}
+
+// Register makes e the extractor of each key, a media type such as
+// "application/rss+xml" or a file extension such as ".rss"
+// A key registered again is taken over by the later extractor
+func (r *Extractors) Register(e Extractor, keys ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, key := range keys {
+		key = strings.ToLower(key)
+		if strings.HasPrefix(key, ".") {
+			r.byExt[key] = e
+		} else {
+			r.byType[key] = e
+		}
+	}
+}
+
+// Lookup returns the extractor of a document, or nil when none is registered
+// A Content-Type decides on its own, so a PDF or a zip served from an .html
+// URL is never parsed; without one the extension of name decides, as for
+// files on disk
+func (r *Extractors) Lookup(contentType, name string) Extractor {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if contentType != "" {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err != nil {
+			return nil
+		}
+		return r.byType[mediaType]
+	}
+	return r.byExt[strings.ToLower(path.Ext(name))]
+}
+
+// Extensions returns the registered file extensions, sorted
+func (r *Extractors) Extensions() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return slices.Sorted(maps.Keys(r.byExt))
+}
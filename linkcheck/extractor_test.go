@@ -0,0 +1,99 @@
+package linkcheck
+
+import (
+	"io"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestExtractors_Lookup(t *testing.T) {
+	extractors := NewExtractors()
+
+	tests := []struct {
+		contentType string
+		name        string
+		want        Extractor
+	}{
+		{contentType: "text/html; charset=utf-8", name: "/", want: HTMLExtractor{}},
+		{contentType: "TEXT/HTML", name: "/", want: HTMLExtractor{}},
+		{contentType: "text/markdown", name: "/notes", want: MarkdownExtractor{}},
+		{contentType: "application/pdf", name: "/manual.pdf"},
+		{contentType: "application/zip", name: "/index.html"}, // the Content-Type decides
+		{contentType: "not a type;", name: "/index.html"},
+		{name: "docs/README.MD", want: MarkdownExtractor{}},
+		{name: "public/index.htm", want: HTMLExtractor{}},
+		{name: "urls.txt", want: URLListExtractor{}},
+		{name: "data.csv"},
+		{name: "Makefile"},
+	}
+
+	for _, tt := range tests {
+		if got := extractors.Lookup(tt.contentType, tt.name); got != tt.want {
+			t.Errorf("Lookup(%q, %q) = %#v, want %#v", tt.contentType, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestExtractors_Register(t *testing.T) {
+	extractors := NewExtractors()
+	feed := ExtractorFunc(func(body io.Reader, baseURL *url.URL) ([]Link, error) {
+		return []Link{{URL: resolveLink(baseURL, "/post"), Kind: KindLinks}}, nil
+	})
+	extractors.Register(feed, "application/RSS+xml", ".RSS")
+
+	for _, key := range [][2]string{{"application/rss+xml", ""}, {"", "feed.rss"}} {
+		extractor := extractors.Lookup(key[0], key[1])
+		if extractor == nil {
+			t.Fatalf("Lookup(%q, %q) = nil, want the registered extractor", key[0], key[1])
+		}
+		base, _ := url.Parse("https://example.com/feed.rss")
+		if links, err := extractor.Extract(strings.NewReader(""), base); err != nil || len(links) != 1 || links[0].URL != "https://example.com/post" {
+			t.Errorf("Extract() = %+v, %v, want the link of the registered extractor", links, err)
+		}
+	}
+
+	if got := strings.Join(extractors.Extensions(), " "); got != ".htm .html .markdown .md .rss .txt .xhtml" {
+		t.Errorf("Extensions() = %q", got)
+	}
+	if NewExtractors().Lookup("", "feed.rss") != nil {
+		t.Error("Register() changed a registry other than its own")
+	}
+}
+
+func TestMarkdownExtractor(t *testing.T) {
+	content := "See [the guide](guide.md#setup) and `https://example.com/code`.\n"
+	base, _ := url.Parse("https://example.com/docs/")
+
+	links, err := MarkdownExtractor{}.Extract(strings.NewReader(content), base)
+	if err != nil || len(links) != 1 || links[0].URL != "https://example.com/docs/guide.md#setup" || links[0].Line != 1 {
+		t.Errorf("Extract() = %+v, %v, want the link resolved against the base", links, err)
+	}
+
+	// without a base the link is kept as written, to be checked on disk
+	links, err = MarkdownExtractor{IncludeCode: true}.Extract(strings.NewReader(content), nil)
+	if err != nil || len(links) != 2 || links[0].URL != "guide.md#setup" || links[1].URL != "https://example.com/code" {
+		t.Errorf("Extract() = %+v, %v, want the link as written and the code URL", links, err)
+	}
+}
+
+func TestURLListExtractor(t *testing.T) {
+	content := "# a comment\nhttps://example.com\n\n  https://example.org\n"
+
+	links, err := URLListExtractor{}.Extract(strings.NewReader(content), nil)
+	if err != nil {
+		t.Fatalf("Extract() error = %v", err)
+	}
+	want := []Link{
+		{URL: "https://example.com", Kind: KindLinks, Line: 2, Column: 1},
+		{URL: "https://example.org", Kind: KindLinks, Line: 4, Column: 3},
+	}
+	if len(links) != len(want) {
+		t.Fatalf("Extract() = %+v, want %+v", links, want)
+	}
+	for i := range want {
+		if links[i] != want[i] {
+			t.Errorf("links[%d] = %+v, want %+v", i, links[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,80 @@
+// fetcher.go - Fetcher interface and its HTTP implementation
+
+package linkcheck
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Fetcher fetches a single URL for checking and crawling
+// A redirect is returned as is, with its Location header, so the checker can
+// record every hop. Implementations must be safe for concurrent use
+type Fetcher interface {
+	Fetch(ctx context.Context, req *Request) (*Response, error)
+}
+
+// Request is a URL to fetch
+type Request struct {
+	Method string // http.MethodGet, or http.MethodHead when the body is not needed
+	URL    *url.URL
+}
+
+// Response is what a Fetcher got for a Request
+type Response struct {
+	URL      *url.URL      // URL that answered, relative Location headers resolve against it
+	Status   int           // HTTP status code, or the code a web server would send
+	Header   http.Header   // Content-Type picks the document parser, Location and Retry-After are followed
+	Body     io.ReadCloser // the caller must close it, also for HEAD
+	Duration time.Duration // time until the status and headers were received
+}
+
+// HTTPFetcher fetches http and https URLs with an http.Client
+type HTTPFetcher struct {
+	Client *http.Client // nil means http.DefaultClient
+}
+
+// Fetch sends the request without following redirects
+func (f *HTTPFetcher) Fetch(ctx context.Context, req *Request) (*Response, error) {
+	client := *cmp.Or(f.Client, http.DefaultClient)
+	client.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, req.Method, req.URL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	return &Response{
+		URL:      resp.Request.URL,
+		Status:   resp.StatusCode,
+		Header:   resp.Header,
+		Body:     resp.Body,
+		Duration: time.Since(start),
+	}, nil
+}
+
+// SchemeFetcher sends each request to the Fetcher registered for its URL scheme,
+// such as "http" or "https"
+type SchemeFetcher map[string]Fetcher
+
+// Fetch fetches with the Fetcher of the request's scheme
+func (f SchemeFetcher) Fetch(ctx context.Context, req *Request) (*Response, error) {
+	fetcher, ok := f[strings.ToLower(req.URL.Scheme)]
+	if !ok {
+		return nil, fmt.Errorf("unsupported protocol scheme %q", req.URL.Scheme)
+	}
+	return fetcher.Fetch(ctx, req)
+}
@@ -0,0 +1,64 @@
+package linkcheck
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestHTTPFetcher(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/old" {
+			http.Redirect(w, r, "/new", http.StatusMovedPermanently)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		io.WriteString(w, "<p>new</p>")
+	}))
+	defer server.Close()
+
+	fetcher := &HTTPFetcher{Client: &http.Client{Timeout: 5 * time.Second}}
+
+	// redirects are returned, not followed
+	resp, err := fetcher.Fetch(t.Context(), &Request{Method: http.MethodGet, URL: mustParse(t, server.URL+"/old")})
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.Status != http.StatusMovedPermanently || resp.Header.Get("Location") != "/new" {
+		t.Errorf("Fetch(/old) = %d %q, want the 301 to /new", resp.Status, resp.Header.Get("Location"))
+	}
+
+	resp, err = fetcher.Fetch(t.Context(), &Request{Method: http.MethodGet, URL: mustParse(t, server.URL+"/new")})
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.Status != http.StatusOK || string(body) != "<p>new</p>" || resp.Duration <= 0 {
+		t.Errorf("Fetch(/new) = %d %q in %v, want 200 with the page", resp.Status, body, resp.Duration)
+	}
+}
+
+func TestSchemeFetcher(t *testing.T) {
+	fetcher := SchemeFetcher{"https": ReplayFetcher{"https://example.com": {}}}
+
+	if got := checkURL(t.Context(), fetcher, checkOptions{}, "https://example.com"); got.Broken() {
+		t.Errorf("checkURL(https) = %+v, want ok", got)
+	}
+	if got := checkURL(t.Context(), fetcher, checkOptions{}, "ftp://example.com/file"); got.ErrorKind != ErrorInvalidURL {
+		t.Errorf("checkURL(ftp) ErrorKind = %q, want %q", got.ErrorKind, ErrorInvalidURL)
+	}
+}
+
+func mustParse(t *testing.T, rawURL string) *url.URL {
+	t.Helper()
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u
+}
@@ -0,0 +1,304 @@
+// fragment.go - #fragment validation against HTML and Markdown documents
+
+package linkcheck
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"golang.org/x/net/html"
+)
+
+const maxDocumentSize = 10 * 1024 * 1024 // bytes of a document read when looking for anchors
+
+// splitFragment splits a link into the page URL and its decoded fragment
+// Fragments that never need an element, such as "#top" or text fragments, are dropped
+func splitFragment(link string) (string, string) {
+	u, err := url.Parse(link)
+	if err != nil || u.Fragment == "" {
+		return link, ""
+	}
+
+	fragment := u.Fragment
+	u.Fragment = ""
+	u.RawFragment = ""
+	page := u.String()
+
+	if strings.EqualFold(fragment, "top") || strings.HasPrefix(fragment, ":~:") {
+		return page, ""
+	}
+	return page, fragment
+}
+
+// readAnchors reads a response body and returns the anchors it defines
+// The second result is false when the document is neither HTML nor Markdown
+func readAnchors(resp *Response) (map[string]bool, bool) {
+	kind := documentType(resp)
+	if kind == "" {
+		return nil, false
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxDocumentSize))
+	if err != nil {
+		return nil, false
+	}
+	return documentAnchors(kind, body), true
+}
+
+// documentAnchors returns the anchors defined by an "html" or "markdown" document
+func documentAnchors(kind string, body []byte) map[string]bool {
+	if kind == "markdown" {
+		return extractMarkdownAnchors(string(body))
+	}
+	return extractAnchors(bytes.NewReader(body))
+}
+
+// documentType classifies a response as "html", "markdown" or "" from its
+// Content-Type, falling back to the URL's file extension
+func documentType(resp *Response) string {
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	switch {
+	case mediaType == "text/markdown" || mediaType == "text/x-markdown":
+		return "markdown"
+	case mediaType == "text/html" || mediaType == "application/xhtml+xml":
+		return "html"
+	case mediaType == "text/plain" && isMarkdownPath(resp.URL.Path):
+		return "markdown"
+	case mediaType == "":
+		if isMarkdownPath(resp.URL.Path) {
+			return "markdown"
+		}
+		return "html"
+	}
+	return ""
+}
+
+// isMarkdownPath reports whether a path names a Markdown file
+func isMarkdownPath(p string) bool {
+	ext := strings.ToLower(path.Ext(p))
+	return ext == ".md" || ext == ".markdown"
+}
+
+// extractAnchors returns every id and <a name> defined in an HTML document
+// GitHub prefixes rendered Markdown ids with "user-content-" and resolves
+// fragments without it in script, so both forms are recorded
+func extractAnchors(body io.Reader) map[string]bool {
+	anchors := make(map[string]bool)
+	tokenizer := html.NewTokenizer(body)
+
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return anchors
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+			for _, attr := range token.Attr {
+				if attr.Key == "id" || (token.Data == "a" && attr.Key == "name") {
+					if attr.Val != "" {
+						anchors[attr.Val] = true
+						anchors[strings.TrimPrefix(attr.Val, "user-content-")] = true
+					}
+				}
+			}
+		}
+	}
+}
+
+var (
+	atxHeadingRe    = regexp.MustCompile(`^ {0,3}#{1,6}(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	setextUnderline = regexp.MustCompile(`^ {0,3}(?:=+|-+)[ \t]*$`)
+	htmlAnchorRe    = regexp.MustCompile(`<[a-zA-Z][^>]*\s(?:id|name)\s*=\s*["']([^"']+)["']`)
+	inlineLinkRe    = regexp.MustCompile(`!?\[([^\]]*)\]\([^)]*\)`)
+	emphasisRe      = regexp.MustCompile(`(^|[^\p{L}\p{N}_])_+|_+([^\p{L}\p{N}_]|$)`)
+)
+
+// extractMarkdownAnchors returns the GitHub-style slugs of every heading in a
+// Markdown document, plus ids and names of inline HTML elements
+func extractMarkdownAnchors(content string) map[string]bool {
+	anchors := make(map[string]bool)
+	counts := make(map[string]int)
+
+	addHeading := func(text string) {
+		slug := githubSlug(text)
+		if n := counts[slug]; n > 0 {
+			anchors[slug+"-"+strconv.Itoa(n)] = true
+		} else {
+			anchors[slug] = true
+		}
+		counts[slug]++
+	}
+
+	lines := strings.Split(content, "\n")
+	fence := ""
+	for i, line := range lines {
+		line = strings.TrimRight(line, "\r")
+		trimmed := strings.TrimLeft(line, " ")
+
+		// skip fenced code blocks
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fence = trimmed[:3]
+			continue
+		}
+
+		for _, match := range htmlAnchorRe.FindAllStringSubmatch(line, -1) {
+			anchors[match[1]] = true
+		}
+
+		if match := atxHeadingRe.FindStringSubmatch(line); match != nil {
+			addHeading(match[1])
+			continue
+		}
+
+		// setext heading: a paragraph line underlined with === or ---
+		if i+1 < len(lines) && strings.TrimSpace(line) != "" && setextUnderline.MatchString(lines[i+1]) &&
+			!strings.HasPrefix(trimmed, "-") && !strings.HasPrefix(trimmed, "*") {
+			addHeading(strings.TrimSpace(line))
+		}
+	}
+
+	return anchors
+}
+
+// githubSlug converts heading text to the anchor GitHub generates for it:
+// markup removed, lowercased, punctuation dropped and spaces turned into hyphens
+func githubSlug(text string) string {
+	text = inlineLinkRe.ReplaceAllString(text, "$1")
+	text = emphasisRe.ReplaceAllString(text, "$1$2")
+
+	var b strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(text)) {
+		switch {
+		case unicode.IsLetter(r), unicode.IsNumber(r), r == '-', r == '_':
+			b.WriteRune(r)
+		case r == ' ':
+			b.WriteRune('-')
+		}
+	}
+	return b.String()
+}
+
+// anchorCache remembers the anchors of each document so a page is fetched
+// at most once however many fragments point into it
+type anchorCache struct {
+	fetcher Fetcher
+	opts    checkOptions
+	pages   map[string]*anchorPage
+	mu      sync.Mutex
+}
+
+// anchorPage is the cached state of a single document
+type anchorPage struct {
+	once    sync.Once
+	anchors map[string]bool
+	ok      bool // false when the page could not be fetched or parsed
+}
+
+// newAnchorCache creates an empty cache that fetches with fetcher
+func newAnchorCache(fetcher Fetcher, opts checkOptions) *anchorCache {
+	return &anchorCache{
+		fetcher: fetcher,
+		opts:    opts,
+		pages:   make(map[string]*anchorPage),
+	}
+}
+
+// Add stores the anchors of a page the crawler has already fetched
+func (c *anchorCache) Add(pageURL string, anchors map[string]bool) {
+	page := c.page(pageURL)
+	page.once.Do(func() {
+		page.anchors, page.ok = anchors, true
+	})
+}
+
+// Anchors returns the anchors of a page, fetching it on first use
+// ok is false when the page is broken or is not an HTML or Markdown document
+func (c *anchorCache) Anchors(ctx context.Context, pageURL string) (anchors map[string]bool, ok bool) {
+	page := c.page(pageURL)
+	page.once.Do(func() {
+		result := LinkResult{URL: pageURL}
+		resp, err := fetchURL(ctx, c.fetcher, c.opts, http.MethodGet, &result)
+		if err != nil {
+			return
+		}
+		defer resp.Body.Close()
+
+		if resp.Status >= 400 {
+			return
+		}
+		page.anchors, page.ok = readAnchors(resp)
+	})
+	return page.anchors, page.ok
+}
+
+// page returns the cache entry for a URL, creating it on first use
+func (c *anchorCache) page(pageURL string) *anchorPage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	page, ok := c.pages[pageURL]
+	if !ok {
+		page = &anchorPage{}
+		c.pages[pageURL] = page
+	}
+	return page
+}
+
+// fileAnchorCache remembers the anchors of local documents so each file is
+// read at most once
+type fileAnchorCache struct {
+	files map[string]*anchorPage
+	mu    sync.Mutex
+}
+
+// newFileAnchorCache creates an empty cache
+func newFileAnchorCache() *fileAnchorCache {
+	return &fileAnchorCache{files: make(map[string]*anchorPage)}
+}
+
+// Anchors returns the anchors of a local file, reading it on first use
+// ok is false when the file cannot be read or is neither HTML nor Markdown
+func (c *fileAnchorCache) Anchors(file string) (anchors map[string]bool, ok bool) {
+	c.mu.Lock()
+	page, found := c.files[file]
+	if !found {
+		page = &anchorPage{}
+		c.files[file] = page
+	}
+	c.mu.Unlock()
+
+	page.once.Do(func() {
+		var kind string
+		switch {
+		case isHTMLPath(file):
+			kind = "html"
+		case isMarkdownPath(file):
+			kind = "markdown"
+		default:
+			return
+		}
+
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return
+		}
+		page.anchors, page.ok = documentAnchors(kind, content), true
+	})
+	return page.anchors, page.ok
+}
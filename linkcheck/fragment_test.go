@@ -0,0 +1,192 @@
+package linkcheck
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSplitFragment(t *testing.T) {
+	tests := []struct {
+		link         string
+		wantPage     string
+		wantFragment string
+	}{
+		{link: "https://example.com/page#section", wantPage: "https://example.com/page", wantFragment: "section"},
+		{link: "https://example.com/page", wantPage: "https://example.com/page", wantFragment: ""},
+		{link: "https://example.com/page?q=1#results", wantPage: "https://example.com/page?q=1", wantFragment: "results"},
+		{link: "https://example.com/page#caf%C3%A9", wantPage: "https://example.com/page", wantFragment: "café"},
+		{link: "https://example.com/page#top", wantPage: "https://example.com/page", wantFragment: ""},
+		{link: "https://example.com/page#:~:text=hello", wantPage: "https://example.com/page", wantFragment: ""},
+	}
+
+	for _, tt := range tests {
+		page, fragment := splitFragment(tt.link)
+		if page != tt.wantPage || fragment != tt.wantFragment {
+			t.Errorf("splitFragment(%q) = %q, %q, want %q, %q", tt.link, page, fragment, tt.wantPage, tt.wantFragment)
+		}
+	}
+}
+
+func TestExtractAnchors(t *testing.T) {
+	html := `<html><body>
+		<h1 id="intro">Intro</h1>
+		<a name="legacy"></a>
+		<div name="not-an-anchor"></div>
+		<section id="user-content-install"></section>
+		<p id="">Empty</p>
+	</body></html>`
+
+	anchors := extractAnchors(strings.NewReader(html))
+
+	for _, want := range []string{"intro", "legacy", "user-content-install", "install"} {
+		if !anchors[want] {
+			t.Errorf("Expected anchor %q", want)
+		}
+	}
+	for _, unwanted := range []string{"not-an-anchor", ""} {
+		if anchors[unwanted] {
+			t.Errorf("Unexpected anchor %q", unwanted)
+		}
+	}
+}
+
+func TestGithubSlug(t *testing.T) {
+	tests := []struct {
+		heading string
+		want    string
+	}{
+		{heading: "Getting Started", want: "getting-started"},
+		{heading: "What's new in v2.0?", want: "whats-new-in-v20"},
+		{heading: "`go install` usage", want: "go-install-usage"},
+		{heading: "Use **bold** and _emphasis_", want: "use-bold-and-emphasis"},
+		{heading: "snake_case_name", want: "snake_case_name"},
+		{heading: "[Linked](https://example.com) heading", want: "linked-heading"},
+		{heading: "Café & Crème", want: "café--crème"},
+		{heading: "  Trim me  ", want: "trim-me"},
+	}
+
+	for _, tt := range tests {
+		if got := githubSlug(tt.heading); got != tt.want {
+			t.Errorf("githubSlug(%q) = %q, want %q", tt.heading, got, tt.want)
+		}
+	}
+}
+
+func TestExtractMarkdownAnchors(t *testing.T) {
+	content := "# Title\n" +
+		"\n" +
+		"## Install ##\n" +
+		"\n" +
+		"Setext Heading\n" +
+		"--------------\n" +
+		"\n" +
+		"## Usage\n" +
+		"## Usage\n" +
+		"\n" +
+		"```\n" +
+		"# not a heading\n" +
+		"```\n" +
+		"\n" +
+		"<a name=\"custom-anchor\"></a>\n" +
+		"#hashtag is not a heading\n"
+
+	anchors := extractMarkdownAnchors(content)
+
+	for _, want := range []string{"title", "install", "setext-heading", "usage", "usage-1", "custom-anchor"} {
+		if !anchors[want] {
+			t.Errorf("Expected anchor %q, got %v", want, anchors)
+		}
+	}
+	for _, unwanted := range []string{"not-a-heading", "hashtag-is-not-a-heading"} {
+		if anchors[unwanted] {
+			t.Errorf("Unexpected anchor %q", unwanted)
+		}
+	}
+}
+
+func TestDocumentType(t *testing.T) {
+	tests := []struct {
+		contentType string
+		path        string
+		want        string
+	}{
+		{contentType: "text/html; charset=utf-8", path: "/page", want: "html"},
+		{contentType: "text/html", path: "/blob/main/README.md", want: "html"},
+		{contentType: "text/markdown", path: "/README", want: "markdown"},
+		{contentType: "text/plain; charset=utf-8", path: "/raw/README.md", want: "markdown"},
+		{contentType: "text/plain", path: "/notes.txt", want: ""},
+		{contentType: "application/pdf", path: "/manual.pdf", want: ""},
+		{contentType: "", path: "/docs/guide.md", want: "markdown"},
+		{contentType: "", path: "/page", want: "html"},
+	}
+
+	for _, tt := range tests {
+		resp := &Response{
+			URL:    &url.URL{Path: tt.path},
+			Header: http.Header{},
+		}
+		if tt.contentType != "" {
+			resp.Header.Set("Content-Type", tt.contentType)
+		}
+		if got := documentType(resp); got != tt.want {
+			t.Errorf("documentType(%q, %q) = %q, want %q", tt.contentType, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestAnchorCache_FetchesOnce(t *testing.T) {
+	var fetches int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&fetches, 1)
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<h2 id="one">One</h2>`)
+	}))
+	defer server.Close()
+
+	fetcher := &HTTPFetcher{Client: &http.Client{Timeout: 5 * time.Second}}
+	cache := newAnchorCache(fetcher, checkOptions{})
+
+	for range 3 {
+		anchors, ok := cache.Anchors(t.Context(), server.URL)
+		if !ok || !anchors["one"] {
+			t.Fatalf("Anchors() = %v, %v, want anchor \"one\"", anchors, ok)
+		}
+	}
+
+	if fetches != 1 {
+		t.Errorf("Page fetched %d times, want 1", fetches)
+	}
+}
+
+func TestAnchorCache_Add(t *testing.T) {
+	fetcher := &HTTPFetcher{Client: &http.Client{Timeout: 5 * time.Second}}
+	cache := newAnchorCache(fetcher, checkOptions{})
+
+	// a page added by the crawler is never fetched
+	cache.Add("http://invalid.invalid/page", map[string]bool{"known": true})
+
+	anchors, ok := cache.Anchors(t.Context(), "http://invalid.invalid/page")
+	if !ok || !anchors["known"] {
+		t.Errorf("Anchors() = %v, %v, want added anchors", anchors, ok)
+	}
+}
+
+func TestAnchorCache_BrokenPage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	fetcher := &HTTPFetcher{Client: &http.Client{Timeout: 5 * time.Second}}
+	cache := newAnchorCache(fetcher, checkOptions{})
+
+	if _, ok := cache.Anchors(t.Context(), server.URL); ok {
+		t.Error("Expected broken page to have no anchors")
+	}
+}
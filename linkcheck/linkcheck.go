@@ -0,0 +1,317 @@
+// Package linkcheck checks that the links of web sites, static sites built to
+// a directory and Markdown documents work.
+//
+// A Checker is created with New and configured with options:
+//
+//	checker, err := linkcheck.New(
+//		linkcheck.WithConcurrency(4),
+//		linkcheck.WithRetries(2, time.Second),
+//	)
+//	if err != nil {
+//		return err
+//	}
+//	report := checker.Crawl(ctx, "https://example.com")
+//	for _, result := range report.Results {
+//		if result.Broken() {
+//			fmt.Println(result.URL, result.Status, result.Error)
+//		}
+//	}
+//
+// Cancelling ctx, or reaching its deadline, stops a check gracefully: links
+// not checked yet are dropped, requests already sent finish, and the report
+// holds the results so far with Incomplete set
+package linkcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultTimeout      = 10 * time.Second // default HTTP request timeout
+	DefaultRetryBackoff = time.Second      // default delay before the first retry
+)
+
+// Checker checks links over HTTP, or with a Fetcher, and on disk
+// A Checker is safe for concurrent use, and each call starts from a clean
+// state, so a page checked by one call is fetched again by the next
+type Checker struct {
+	client    *http.Client // nil until New builds it, unless set by WithClient
+	fetcher   Fetcher      // an HTTPFetcher with client, unless set by WithFetcher
+	timeout   time.Duration
+	rates     RateLimits
+	header    http.Header
+	checkOpts checkOptions
+	crawlOpts crawlOptions
+}
+
+// Option configures a Checker
+type Option func(*Checker)
+
+// Report is the outcome of a check
+type Report struct {
+	Results    []LinkResult
+	Truncated  bool // links were dropped because WithMaxPages or WithMaxExternal was reached
+	Incomplete bool // ctx was done before every link was checked
+}
+
+// New creates a Checker
+// Without options it checks with a 10s timeout, 10 concurrent requests and no
+// retries, and crawls 2 levels deep while obeying robots.txt
+func New(opts ...Option) (*Checker, error) {
+	c := &Checker{
+		timeout: DefaultTimeout,
+		checkOpts: checkOptions{
+			Concurrency:  DefaultConcurrency,
+			RetryBackoff: DefaultRetryBackoff,
+			MaxRedirects: DefaultMaxRedirects,
+		},
+		crawlOpts: crawlOptions{
+			MaxDepth:  DefaultMaxDepth,
+			UserAgent: DefaultUserAgent,
+		},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+
+	if c.fetcher != nil {
+		return c, nil
+	}
+	if c.client == nil {
+		header := c.header.Clone()
+		if header == nil {
+			header = make(http.Header)
+		}
+		header.Set("User-Agent", c.crawlOpts.UserAgent)
+		c.client = &http.Client{
+			Transport: &headerTransport{
+				next:   newPoliteTransport(http.DefaultTransport, c.rates, c.timeout),
+				header: header,
+			},
+		}
+	}
+	c.fetcher = &HTTPFetcher{Client: c.client}
+	return c, nil
+}
+
+// validate reports the first setting that is out of range
+func (c *Checker) validate() error {
+	switch {
+	case c.timeout < 0:
+		return errors.New("linkcheck: timeout must not be negative")
+	case c.checkOpts.Concurrency < 1:
+		return errors.New("linkcheck: concurrency must be at least 1")
+	case c.checkOpts.Retries < 0 || c.checkOpts.RetryBackoff < 0:
+		return errors.New("linkcheck: retries and retry backoff must not be negative")
+	case c.checkOpts.MaxRedirects < 1:
+		return errors.New("linkcheck: max redirects must be at least 1")
+	case c.checkOpts.SlowThreshold < 0:
+		return errors.New("linkcheck: slow threshold must not be negative")
+	case c.crawlOpts.MaxDepth < 0 || c.crawlOpts.MaxPages < 0 || c.crawlOpts.MaxExternal < 0:
+		return errors.New("linkcheck: max depth, max pages and max external must not be negative")
+	case c.rates.HostConcurrency < 0:
+		return errors.New("linkcheck: host concurrency must not be negative")
+	}
+	for _, kind := range c.crawlOpts.Check {
+		if !slices.Contains(LinkKinds, kind) {
+			return fmt.Errorf("linkcheck: unknown kind %q, expected %s", kind, strings.Join(LinkKinds, ","))
+		}
+	}
+	return nil
+}
+
+// WithClient sends requests with client as is, instead of a client built from
+// WithTimeout, WithRateLimits, WithHeader and WithUserAgent
+func WithClient(client *http.Client) Option {
+	return func(c *Checker) { c.client = client }
+}
+
+// WithFetcher fetches URLs with fetcher instead of over HTTP, for instance to
+// check other schemes, replay recorded responses or cache them. WithClient,
+// WithTimeout, WithRateLimits and WithHeader have no effect with it
+func WithFetcher(fetcher Fetcher) Option {
+	return func(c *Checker) { c.fetcher = fetcher }
+}
+
+// WithTimeout sets the timeout of a single request, 0 means none
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Checker) { c.timeout = timeout }
+}
+
+// WithRateLimits limits how hard a single host is hit
+func WithRateLimits(limits RateLimits) Option {
+	return func(c *Checker) { c.rates = limits }
+}
+
+// WithHeader sends header with every request
+func WithHeader(header http.Header) Option {
+	return func(c *Checker) { c.header = header }
+}
+
+// WithUserAgent sets the User-Agent header, which is also matched against
+// robots.txt groups
+func WithUserAgent(userAgent string) Option {
+	return func(c *Checker) { c.crawlOpts.UserAgent = userAgent }
+}
+
+// WithConcurrency sets the maximum number of concurrent requests
+func WithConcurrency(n int) Option {
+	return func(c *Checker) { c.checkOpts.Concurrency = n }
+}
+
+// WithRetries retries a network error, 429 or 5xx response up to n times,
+// waiting backoff before the first retry and doubling it for each further one
+func WithRetries(n int, backoff time.Duration) Option {
+	return func(c *Checker) { c.checkOpts.Retries, c.checkOpts.RetryBackoff = n, backoff }
+}
+
+// WithMaxRedirects sets the number of redirects followed before a link is broken
+func WithMaxRedirects(n int) Option {
+	return func(c *Checker) { c.checkOpts.MaxRedirects = n }
+}
+
+// WithGetOnly checks the links of hosts with GET instead of HEAD
+func WithGetOnly(hosts ...string) Option {
+	return func(c *Checker) { c.checkOpts.GetOnlyHosts = append(c.checkOpts.GetOnlyHosts, hosts...) }
+}
+
+// WithRules reports the URLs excluded by rules instead of checking them
+func WithRules(rules URLRules) Option {
+	return func(c *Checker) { c.checkOpts.Rules = rules }
+}
+
+// WithStatusRules sets the status codes that are ok or a warning per host or URL pattern
+func WithStatusRules(rules ...StatusRule) Option {
+	return func(c *Checker) { c.checkOpts.StatusRules = append(c.checkOpts.StatusRules, rules...) }
+}
+
+// WithSlowThreshold makes links slower than threshold to respond warnings
+func WithSlowThreshold(threshold time.Duration) Option {
+	return func(c *Checker) { c.checkOpts.SlowThreshold = threshold }
+}
+
+// WithOnResult calls fn with each result as soon as it completes, so results
+// can be shown or stored while a long run is still going. Calls are never
+// concurrent, and a result carries the locations found so far; the Report
+// lists every location
+func WithOnResult(fn func(LinkResult)) Option {
+	return func(c *Checker) {
+		var mu sync.Mutex
+		c.checkOpts.OnResult = func(result LinkResult) {
+			mu.Lock()
+			defer mu.Unlock()
+			fn(result)
+		}
+	}
+}
+
+// WithMaxDepth sets the link depth a crawl follows from its start URL
+func WithMaxDepth(depth int) Option {
+	return func(c *Checker) { c.crawlOpts.MaxDepth = depth }
+}
+
+// WithMaxPages limits the same-domain pages a crawl fetches, 0 means unlimited
+func WithMaxPages(n int) Option {
+	return func(c *Checker) { c.crawlOpts.MaxPages = n }
+}
+
+// WithMaxExternal limits the external links a crawl or site check checks, 0 means unlimited
+func WithMaxExternal(n int) Option {
+	return func(c *Checker) { c.crawlOpts.MaxExternal = n }
+}
+
+// WithIgnoreRobots crawls pages even if robots.txt disallows them
+func WithIgnoreRobots(ignore bool) Option {
+	return func(c *Checker) { c.crawlOpts.IgnoreRobots = ignore }
+}
+
+// WithExtractors picks the parser of each page a crawl follows, and of each
+// file of a site check, from extractors instead of DefaultExtractors
+func WithExtractors(extractors *Extractors) Option {
+	return func(c *Checker) { c.crawlOpts.Extractors = extractors }
+}
+
+// WithCheck limits the links checked by a crawl or site check to kinds,
+// see LinkKinds; without it every kind is checked
+func WithCheck(kinds ...string) Option {
+	return func(c *Checker) { c.crawlOpts.Check = append(c.crawlOpts.Check, kinds...) }
+}
+
+// Check checks URLs without following their links
+// Results are returned in the same order as urls
+func (c *Checker) Check(ctx context.Context, urls []string) *Report {
+	return &Report{Results: checkURLs(ctx, c.fetcher, urls, c.checkOpts), Incomplete: ctx.Err() != nil}
+}
+
+// CheckLinks checks links found in files, such as Markdown documents
+// Each URL is checked once and reported with every place it appears.
+// Relative links are checked against the file system, relative to their
+// Source, every other link over HTTP, and suppressed links are reported
+// without being checked
+func (c *Checker) CheckLinks(ctx context.Context, links []FileLink) *Report {
+	var urls []string
+	var local []FileLink
+	var suppressed []LinkResult
+	locations := newLocationSet()
+	for _, l := range links {
+		switch {
+		case l.Link.Suppressed:
+			suppressed = append(suppressed, l.Link.SuppressedResult(l.Source))
+		case l.Source != "" && IsRelativeLink(l.Link.URL):
+			local = append(local, l)
+		default:
+			if _, seen := locations.byURL[l.Link.URL]; !seen {
+				urls = append(urls, l.Link.URL)
+			}
+			locations.Add(l.Link.URL, l.Link.At(l.Source))
+		}
+	}
+
+	results := checkURLs(ctx, c.fetcher, urls, c.checkOpts.withLocations(locations))
+	locations.Attach(results)
+
+	// local and suppressed links are reported once the HTTP checks are done
+	for _, result := range slices.Concat(checkLocalLinks(local, c.checkOpts), suppressed) {
+		c.checkOpts.emit(result)
+		results = append(results, result)
+	}
+	return &Report{Results: results, Incomplete: ctx.Err() != nil}
+}
+
+// Crawl checks startURL and follows the links of same-domain pages up to
+// WithMaxDepth, checking every other link without following it
+func (c *Checker) Crawl(ctx context.Context, startURL string) *Report {
+	budget := newCrawlBudget(c.crawlOpts)
+	results := crawl(ctx, c.fetcher, startURL, budget, c.checkOpts)
+	return &Report{Results: results, Truncated: budget.Truncated(), Incomplete: ctx.Err() != nil}
+}
+
+// CheckSite checks the links of every HTML or Markdown file under root as if the
+// directory were published at baseURL; links under it are checked on disk and every other
+// link over HTTP. A nil baseURL checks every absolute http(s) link over HTTP
+func (c *Checker) CheckSite(ctx context.Context, root string, baseURL *url.URL) (*Report, error) {
+	if baseURL == nil {
+		baseURL = &url.URL{Scheme: "file", Path: "/"}
+	}
+	budget := newCrawlBudget(crawlOptions{
+		MaxExternal: c.crawlOpts.MaxExternal,
+		Check:       c.crawlOpts.Check,
+		Extractors:  c.crawlOpts.Extractors,
+	})
+	results, err := checkSite(ctx, c.fetcher, root, baseURL, budget, c.checkOpts)
+	if err != nil {
+		return nil, err
+	}
+	return &Report{Results: results, Truncated: budget.Truncated(), Incomplete: ctx.Err() != nil}, nil
+}
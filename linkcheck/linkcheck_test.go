@@ -0,0 +1,253 @@
+package linkcheck
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNew_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		option  Option
+		wantErr string
+	}{
+		{name: "concurrency", option: WithConcurrency(0), wantErr: "concurrency must be at least 1"},
+		{name: "retries", option: WithRetries(-1, time.Second), wantErr: "retries and retry backoff"},
+		{name: "redirects", option: WithMaxRedirects(0), wantErr: "max redirects must be at least 1"},
+		{name: "depth", option: WithMaxDepth(-1), wantErr: "must not be negative"},
+		{name: "kind", option: WithCheck("links", "videos"), wantErr: `unknown kind "videos"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(tt.option); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("New() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestChecker_Check(t *testing.T) {
+	var userAgent, token string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent, token = r.Header.Get("User-Agent"), r.Header.Get("Authorization")
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	checker, err := New(
+		WithConcurrency(1),
+		WithUserAgent("docs-build/2.0"),
+		WithHeader(http.Header{"Authorization": {"Bearer x"}}),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	report := checker.Check(t.Context(), []string{server.URL + "/ok", server.URL + "/missing"})
+	if len(report.Results) != 2 || report.Results[0].Broken() || !report.Results[1].Broken() {
+		t.Fatalf("Check() = %+v, want ok then broken", report.Results)
+	}
+	if userAgent != "docs-build/2.0" || token != "Bearer x" {
+		t.Errorf("headers = %q, %q, want the configured user agent and header", userAgent, token)
+	}
+}
+
+func TestChecker_WithFetcher(t *testing.T) {
+	checker, err := New(WithFetcher(ReplayFetcher{
+		"https://example.com/ok":      {},
+		"https://example.com/missing": {Status: http.StatusNotFound},
+	}))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	report := checker.Check(t.Context(), []string{"https://example.com/ok", "https://example.com/missing"})
+	if len(report.Results) != 2 || report.Results[0].Broken() || !report.Results[1].Broken() {
+		t.Errorf("Check() = %+v, want ok then broken from the replayed responses", report.Results)
+	}
+}
+
+func TestChecker_CheckLinks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	readme := filepath.Join(dir, "README.md")
+	if err := os.WriteFile(filepath.Join(dir, "guide.md"), []byte("# Guide\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	checker, err := New(WithClient(&http.Client{Timeout: 5 * time.Second}))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	report := checker.CheckLinks(t.Context(), []FileLink{
+		{Source: readme, Link: Link{URL: server.URL, Line: 3, Column: 1}},
+		{Source: readme, Link: Link{URL: "guide.md#guide", Line: 5, Column: 1}},
+		{Source: readme, Link: Link{URL: server.URL, Line: 7, Column: 1}},
+		{Source: readme, Link: Link{URL: server.URL + "/legacy", Line: 9, Column: 1, Suppressed: true}},
+		{Link: Link{URL: server.URL}},
+	})
+
+	if len(report.Results) != 3 {
+		t.Fatalf("CheckLinks() = %d results, want the server, the local file and the suppressed link", len(report.Results))
+	}
+	if got := report.Results[0]; got.URL != server.URL || got.Broken() || len(got.Locations) != 2 {
+		t.Errorf("Results[0] = %+v, want the server with both file locations", got)
+	}
+	if got := report.Results[1]; !strings.HasSuffix(got.URL, "guide.md#guide") || got.Broken() {
+		t.Errorf("Results[1] = %+v, want the local file checked on disk", got)
+	}
+	if got := report.Results[2]; got.Skipped != SkipSuppressed || got.Line != 9 {
+		t.Errorf("Results[2] = %+v, want the suppressed link", got)
+	}
+}
+
+func TestChecker_Crawl(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<html><body><a href="/a">A</a><a href="/b">B</a></body></html>`)
+	})
+	mux.HandleFunc("/a", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/b", func(w http.ResponseWriter, r *http.Request) {})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	checker, err := New(WithMaxPages(2), WithIgnoreRobots(true))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	report := checker.Crawl(t.Context(), server.URL)
+	if len(report.Results) != 2 || !report.Truncated {
+		t.Errorf("Crawl() = %d results, truncated %v, want 2 pages and truncated", len(report.Results), report.Truncated)
+	}
+}
+
+func TestChecker_Crawl_Stopped(t *testing.T) {
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<html><body><a href="/a">A</a><a href="/b">B</a><a href="/c">C</a></body></html>`)
+	})
+	// the run is stopped while /a is in flight, so /a finishes and the rest are dropped
+	mux.HandleFunc("/a", func(w http.ResponseWriter, r *http.Request) { cancel() })
+	mux.HandleFunc("/b", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/c", func(w http.ResponseWriter, r *http.Request) {})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	checker, err := New(WithConcurrency(1), WithIgnoreRobots(true))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	report := checker.Crawl(ctx, server.URL)
+	if len(report.Results) != 2 || !report.Incomplete {
+		t.Fatalf("Crawl() = %+v, incomplete %v, want 2 results and incomplete", report.Results, report.Incomplete)
+	}
+	if got := report.Results[1]; got.URL != server.URL+"/a" || got.Broken() {
+		t.Errorf("Results[1] = %+v, want /a checked", got)
+	}
+}
+
+func TestChecker_OnResult(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<html><body><a href="/a">A</a><a href="/missing">Missing</a></body></html>`)
+	})
+	mux.HandleFunc("/a", func(w http.ResponseWriter, r *http.Request) {})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	var streamed []LinkResult
+	checker, err := New(WithIgnoreRobots(true), WithOnResult(func(result LinkResult) {
+		streamed = append(streamed, result)
+	}))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	report := checker.Crawl(t.Context(), server.URL)
+	if len(streamed) != 3 || len(streamed) != len(report.Results) {
+		t.Fatalf("streamed %d results, want the 3 results of the report", len(streamed))
+	}
+	for _, result := range streamed {
+		if result.URL == server.URL+"/missing" && len(result.Locations) != 1 {
+			t.Errorf("streamed %+v, want the page it was found on", result)
+		}
+	}
+
+	// links found in files are streamed with every location known up front
+	streamed = nil
+	readme := filepath.Join(t.TempDir(), "README.md")
+	checker.CheckLinks(t.Context(), []FileLink{
+		{Source: readme, Link: Link{URL: server.URL + "/a", Line: 1, Column: 1}},
+		{Source: readme, Link: Link{URL: server.URL + "/a", Line: 2, Column: 1}},
+		{Source: readme, Link: Link{URL: "missing.md", Line: 3, Column: 1}},
+	})
+	if len(streamed) != 2 || len(streamed[0].Locations) != 2 || !streamed[1].Broken() {
+		t.Errorf("streamed %+v, want the URL with both locations, then the missing file", streamed)
+	}
+}
+
+func TestChecker_CheckSite(t *testing.T) {
+	root := writeSite(t, map[string]string{
+		"index.html": `<a href="/about/">About</a><a href="/missing">Missing</a>`,
+		"about.html": `<p>About</p>`,
+	})
+
+	checker, err := New()
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	// without a base URL, root-relative links resolve against file:///
+	report, err := checker.CheckSite(t.Context(), root, nil)
+	if err != nil {
+		t.Fatalf("CheckSite() error = %v", err)
+	}
+	if len(report.Results) != 2 {
+		t.Errorf("CheckSite() = %+v, want 2 results", report.Results)
+	}
+
+	if _, err := checker.CheckSite(t.Context(), filepath.Join(root, "nope"), nil); err == nil {
+		t.Error("CheckSite() error = nil for a missing directory")
+	}
+}
+
+func TestChecker_Crawl_ReplayFetcher(t *testing.T) {
+	// robots.txt is not recorded, so the replayed site has none
+	checker, err := New(WithFetcher(ReplayFetcher{
+		"https://example.com/":     {Body: `<a href="/gone">Gone</a>`},
+		"https://example.com/gone": {Status: 404},
+	}))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	report := checker.Crawl(t.Context(), "https://example.com/")
+	byURL := make(map[string]LinkResult)
+	for _, result := range report.Results {
+		byURL[result.URL] = result
+	}
+	if got := byURL["https://example.com/"]; got.Broken() || got.Skipped != "" {
+		t.Errorf("start page = %+v, want crawled", got)
+	}
+	if got := byURL["https://example.com/gone"]; got.Status != 404 {
+		t.Errorf("gone = %+v, want 404", got)
+	}
+}
@@ -0,0 +1,90 @@
+// local.go - Checking relative Markdown links against the filesystem
+
+package linkcheck
+
+import (
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+)
+
+// FileLink is a link found in a file, or given on its own with an empty Source
+type FileLink struct {
+	Source string // path of the file containing the link
+	Link   Link   // link as written, e.g. "../guide/install.md#setup"
+}
+
+// checkLocalLinks checks that the targets of relative links exist on disk and
+// that their fragments name a heading or anchor of the target document
+// Links resolving to the same target are reported once with every location,
+// and each target file is read at most once
+func checkLocalLinks(links []FileLink, opts checkOptions) []LinkResult {
+	anchors := newFileAnchorCache()
+	var results []LinkResult
+	index := make(map[string]int) // position in results by resolved URL
+
+	for _, l := range links {
+		result := checkLocalLink(anchors, opts, l)
+		if i, ok := index[result.URL]; ok {
+			results[i].AddLocation(l.Link.At(l.Source))
+			continue
+		}
+		index[result.URL] = len(results)
+		results = append(results, result)
+	}
+	return results
+}
+
+// checkLocalLink checks a single relative link
+// Existing targets are reported with the status a file server would send.
+// Exclusions match the resolved path as reported, e.g. "docs/img/logo.png"
+func checkLocalLink(anchors *fileAnchorCache, opts checkOptions, l FileLink) LinkResult {
+	result := LinkResult{URL: l.Link.URL}
+	result.AddLocation(l.Link.At(l.Source))
+
+	u, err := url.Parse(l.Link.URL)
+	if err != nil {
+		result.fail(err)
+		return result
+	}
+
+	// "#heading" alone points into the source file itself, a path starting
+	// with "/" is relative to the working directory (usually the repository root)
+	target := l.Source
+	switch {
+	case u.Path == "":
+	case u.Path[0] == '/':
+		target = filepath.Clean("." + u.Path)
+	default:
+		target = filepath.Join(filepath.Dir(l.Source), filepath.FromSlash(u.Path))
+	}
+
+	result.URL = filepath.ToSlash(target)
+	if u.Fragment != "" {
+		result.URL += "#" + u.EscapedFragment()
+	}
+	if excluded, ok := opts.excluded(result.URL); ok {
+		excluded.AddLocation(l.Link.At(l.Source))
+		return excluded
+	}
+
+	info, err := os.Stat(target)
+	if err != nil {
+		result.Status = http.StatusNotFound
+		result.Severity = SeverityError
+		return result
+	}
+	result.Status = http.StatusOK
+	result.Severity = SeverityOK
+
+	// a fragment must name a heading or anchor of the target document
+	_, fragment := splitFragment(l.Link.URL)
+	if fragment != "" && !info.IsDir() {
+		if targetAnchors, ok := anchors.Anchors(target); ok && !targetAnchors[fragment] {
+			result.Fragment = fragment
+			result.Severity = SeverityError
+		}
+	}
+	return result
+}
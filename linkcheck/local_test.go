@@ -0,0 +1,126 @@
+package linkcheck
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckLocalLinks(t *testing.T) {
+	root := writeSite(t, map[string]string{
+		"README.md":               "# Project\n\n## Usage\n",
+		"docs/guide/install.md":   "# Install\n\n## Requirements\n",
+		"docs/guide/page.html":    `<h2 id="intro">Intro</h2>`,
+		"docs/img/arch.png":       "png",
+		"docs/reference/index.md": "# Reference\n",
+	})
+
+	// root-relative links resolve against the working directory
+	wd, _ := os.Getwd()
+	if err := os.Chdir(root); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	source := filepath.Join("docs", "intro", "start.md")
+	tests := []struct {
+		link         string
+		wantURL      string
+		wantStatus   int
+		wantBroken   bool
+		wantFragment string
+	}{
+		{link: "../guide/install.md", wantURL: "docs/guide/install.md", wantStatus: 200},
+		{link: "../img/arch.png", wantURL: "docs/img/arch.png", wantStatus: 200},
+		{link: "../reference/", wantURL: "docs/reference", wantStatus: 200},
+		{link: "../guide/missing.md", wantURL: "docs/guide/missing.md", wantStatus: 404, wantBroken: true},
+		{link: "../guide/install.md#requirements", wantURL: "docs/guide/install.md#requirements", wantStatus: 200},
+		{link: "../guide/install.md#nowhere", wantURL: "docs/guide/install.md#nowhere", wantStatus: 200, wantBroken: true, wantFragment: "nowhere"},
+		{link: "../guide/page.html#intro", wantURL: "docs/guide/page.html#intro", wantStatus: 200},
+		{link: "/README.md#usage", wantURL: "README.md#usage", wantStatus: 200},
+		{link: "../img/arch%20v2.png", wantURL: "docs/img/arch v2.png", wantStatus: 404, wantBroken: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.link, func(t *testing.T) {
+			got := checkLocalLinks([]FileLink{{Source: source, Link: Link{URL: tt.link}}}, checkOptions{})[0]
+
+			if got.URL != tt.wantURL {
+				t.Errorf("URL = %q, want %q", got.URL, tt.wantURL)
+			}
+			if got.Status != tt.wantStatus || got.Broken() != tt.wantBroken {
+				t.Errorf("Status = %d, Broken() = %v, want %d, %v", got.Status, got.Broken(), tt.wantStatus, tt.wantBroken)
+			}
+			if got.Fragment != tt.wantFragment {
+				t.Errorf("Fragment = %q, want %q", got.Fragment, tt.wantFragment)
+			}
+			if got.SourceURL != source {
+				t.Errorf("SourceURL = %q, want %q", got.SourceURL, source)
+			}
+		})
+	}
+}
+
+func TestCheckLocalLinks_SameFile(t *testing.T) {
+	root := writeSite(t, map[string]string{
+		"README.md": "# Project\n\n## Getting Started\n",
+	})
+	source := filepath.Join(root, "README.md")
+
+	results := checkLocalLinks([]FileLink{
+		{Source: source, Link: Link{URL: "#getting-started"}},
+		{Source: source, Link: Link{URL: "#missing", Line: 5, Column: 3}},
+	}, checkOptions{})
+
+	if results[0].Broken() {
+		t.Errorf("Expected #getting-started to be found in the source file")
+	}
+	if !results[1].Broken() || results[1].Fragment != "missing" {
+		t.Errorf("Expected #missing to be a broken fragment, got %+v", results[1])
+	}
+	if results[1].Line != 5 || results[1].Column != 3 {
+		t.Errorf("Position = %d:%d, want 5:3", results[1].Line, results[1].Column)
+	}
+	if results[1].URL != filepath.ToSlash(source)+"#missing" {
+		t.Errorf("URL = %q, want the source file", results[1].URL)
+	}
+}
+
+func TestCheckLocalLinks_Locations(t *testing.T) {
+	root := writeSite(t, map[string]string{
+		"docs/a.md": "",
+		"docs/b.md": "",
+	})
+
+	results := checkLocalLinks([]FileLink{
+		{Source: filepath.Join(root, "docs", "a.md"), Link: Link{URL: "missing.md", Line: 1, Column: 1}},
+		{Source: filepath.Join(root, "docs", "b.md"), Link: Link{URL: "./missing.md", Line: 4, Column: 9}},
+		{Source: filepath.Join(root, "README.md"), Link: Link{URL: "docs/missing.md", Line: 2, Column: 3}},
+	}, checkOptions{})
+
+	if len(results) != 1 {
+		t.Fatalf("Got %d results, want 1 for links resolving to the same file", len(results))
+	}
+	if got := len(results[0].Locations); got != 3 {
+		t.Errorf("Got %d locations, want 3: %v", got, results[0].Locations)
+	}
+}
+
+func TestCheckLocalLinks_Excluded(t *testing.T) {
+	root := writeSite(t, map[string]string{"docs/guide.md": ""})
+	source := filepath.Join(root, "docs", "guide.md")
+
+	pattern, _ := CompilePattern("*.png")
+	opts := checkOptions{Rules: URLRules{Exclude: []Pattern{pattern}}}
+	results := checkLocalLinks([]FileLink{
+		{Source: source, Link: Link{URL: "img/missing.png", Line: 3, Column: 1}},
+		{Source: source, Link: Link{URL: "missing.md", Line: 4, Column: 1}},
+	}, opts)
+
+	if got := results[0]; got.Skipped != SkipExcluded || got.Broken() || got.Line != 3 {
+		t.Errorf("results[0] = %+v, want the image excluded at its location", got)
+	}
+	if !results[1].Broken() {
+		t.Errorf("results[1] = %+v, want the missing page checked", results[1])
+	}
+}
@@ -0,0 +1,206 @@
+// markdown.go - CommonMark link extraction
+
+package linkcheck
+
+import (
+	"bytes"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/text"
+)
+
+// MarkdownOptions controls which parts of a Markdown document links are extracted from
+type MarkdownOptions struct {
+	IncludeCode bool // also extract bare URLs from code spans and code blocks
+}
+
+// markdownParser parses CommonMark with GitHub-style bare URL autolinks
+var markdownParser = goldmark.New(goldmark.WithExtensions(extension.Linkify)).Parser()
+
+// bareURLRe matches URLs written in code, where no autolinks are recognised
+var bareURLRe = regexp.MustCompile(`https?://[^\s<>"{}|\\^\[\]` + "`" + `()]+`)
+
+// htmlCommentRe matches an HTML comment, capturing its text
+var htmlCommentRe = regexp.MustCompile(`(?s)<!--(.*?)-->`)
+
+// extractMarkdownLinks extracts URLs from Markdown content
+// Supports: [text](url), reference definitions, <url> autolinks and bare URLs
+// URLs are returned in order of appearance in the document
+func extractMarkdownLinks(content string) []string {
+	var urls []string
+	seen := make(map[string]bool)
+
+	// Build result list, removing duplicates while preserving order
+	for _, link := range MarkdownLinks(content, MarkdownOptions{}) {
+		if IsHTTPLink(link.URL) && !link.Suppressed && !seen[link.URL] {
+			urls = append(urls, link.URL)
+			seen[link.URL] = true
+		}
+	}
+
+	return urls
+}
+
+// MarkdownLinks returns every link, image, reference definition and autolink
+// of Markdown content, and the links of its raw HTML, with its position, in order of appearance and
+// including repeated URLs. Links that use a reference definition are
+// reported once, at the definition where the URL is written.
+// Code spans and code blocks are skipped unless opts.IncludeCode is set.
+// Links disabled by an inline directive are returned with Suppressed set
+func MarkdownLinks(content string, opts MarkdownOptions) []Link {
+	source := []byte(content)
+	doc := markdownParser.Parse(text.NewReader(source))
+	lines := newLineIndex(content)
+
+	var links []Link
+	var directives suppressor
+	add := func(destination string, kind string, pos int) {
+		destination = strings.TrimSpace(destination)
+		if destination == "" || destination == "#" {
+			return
+		}
+		link := Link{URL: destination, Kind: kind}
+		link.Line, link.Column = lines.Position(pos)
+		link.Suppressed = directives.suppresses(link.Line)
+		links = append(links, link)
+	}
+
+	// directives are HTML comments, either a block of their own or inline
+	comments := func(start, stop int) {
+		for _, match := range htmlCommentRe.FindAllSubmatchIndex(source[start:stop], -1) {
+			endLine, _ := lines.Position(start + match[1] - 1)
+			directives.comment(string(source[start+match[2]:start+match[3]]), endLine)
+		}
+	}
+
+	// links in raw HTML, such as badges and <img> tags, are found by the
+	// HTML parser and moved to their position in the document
+	htmlLinks := func(start, stop int) {
+		found, _ := ExtractLinks(bytes.NewReader(source[start:stop]), nil)
+		startLine, startColumn := lines.Position(start)
+		for _, link := range found {
+			if link.Line == 1 {
+				link.Column += startColumn - 1
+			}
+			link.Line += startLine - 1
+			link.Suppressed = link.Suppressed || directives.suppresses(link.Line)
+			links = append(links, link)
+		}
+	}
+
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+
+		switch node := n.(type) {
+		case *ast.LinkReferenceDefinition:
+			destination := string(node.Destination)
+			add(destination, KindLinks, locate(source, node.Pos(), node.Destination))
+
+		case *ast.Link:
+			if node.Reference == nil {
+				add(string(node.Destination), KindLinks, locate(source, node.Pos(), node.Destination))
+			}
+
+		case *ast.Image:
+			if node.Reference == nil {
+				add(string(node.Destination), KindImages, locate(source, node.Pos(), node.Destination))
+			}
+
+		case *ast.AutoLink:
+			if node.AutoLinkType == ast.AutoLinkURL {
+				label := node.Label(source)
+				add(trimUnbalanced(string(node.URL(source))), KindLinks, locate(source, node.Pos(), label))
+			}
+
+		case *ast.CodeSpan:
+			if opts.IncludeCode {
+				for c := node.FirstChild(); c != nil; c = c.NextSibling() {
+					if t, ok := c.(*ast.Text); ok {
+						addCodeURLs(t.Segment, source, add)
+					}
+				}
+			}
+			return ast.WalkSkipChildren, nil
+
+		case *ast.HTMLBlock:
+			if node.Lines().Len() > 0 {
+				stop := node.Lines().At(node.Lines().Len() - 1).Stop
+				if node.HasClosure() {
+					stop = node.ClosureLine.Stop
+				}
+				comments(node.Lines().At(0).Start, stop)
+				htmlLinks(node.Lines().At(0).Start, stop)
+			}
+
+		case *ast.RawHTML:
+			if node.Segments.Len() > 0 {
+				start, stop := node.Segments.At(0).Start, node.Segments.At(node.Segments.Len()-1).Stop
+				comments(start, stop)
+				htmlLinks(start, stop)
+			}
+
+		case *ast.FencedCodeBlock, *ast.CodeBlock:
+			if opts.IncludeCode {
+				for i := 0; i < node.Lines().Len(); i++ {
+					addCodeURLs(node.Lines().At(i), source, add)
+				}
+			}
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+
+	return links
+}
+
+// addCodeURLs adds the bare http(s) URLs of a code segment
+func addCodeURLs(segment text.Segment, source []byte, add func(string, string, int)) {
+	code := segment.Value(source)
+	for _, match := range bareURLRe.FindAllIndex(code, -1) {
+		add(string(code[match[0]:match[1]]), KindLinks, segment.Start+match[0])
+	}
+}
+
+// trimUnbalanced drops closing parentheses without an opening one from the
+// end of a bare URL, so "(see https://example.com)" ends before the ")"
+func trimUnbalanced(link string) string {
+	for strings.HasSuffix(link, ")") && strings.Count(link, ")") > strings.Count(link, "(") {
+		link = link[:len(link)-1]
+	}
+	return link
+}
+
+// locate returns the offset of the written destination at or after a node's
+// start, falling back to the node's start when it is written differently,
+// e.g. with escapes or entities
+func locate(source []byte, start int, destination []byte) int {
+	if start < 0 {
+		return 0
+	}
+	if i := bytes.Index(source[start:], destination); i >= 0 && len(destination) > 0 {
+		// only accept a match before the end of the node's paragraph
+		if !bytes.Contains(source[start:start+i], []byte("\n\n")) {
+			return start + i
+		}
+	}
+	return start
+}
+
+// IsHTTPLink reports whether a link is an absolute http(s) URL
+func IsHTTPLink(link string) bool {
+	return strings.HasPrefix(link, "http://") || strings.HasPrefix(link, "https://")
+}
+
+// IsRelativeLink reports whether a link is a relative path or same-file
+// fragment, e.g. "../guide/install.md" or "#usage"
+func IsRelativeLink(link string) bool {
+	u, err := url.Parse(link)
+	return err == nil && u.Scheme == "" && u.Host == "" && !strings.HasPrefix(link, "//")
+}
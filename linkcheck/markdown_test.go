@@ -0,0 +1,323 @@
+package linkcheck
+
+import (
+	"testing"
+)
+
+func TestExtractMarkdownLinks(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		wantURLs []string
+	}{
+		{
+			name: "markdown link syntax",
+			content: `# My Post
+[Google](https://google.com)
+[Example](https://example.com)`,
+			wantURLs: []string{"https://google.com", "https://example.com"},
+		},
+		{
+			name: "bare URLs",
+			content: `Check out https://github.com
+Also see https://golang.org`,
+			wantURLs: []string{"https://github.com", "https://golang.org"},
+		},
+		{
+			name: "mixed markdown links and bare URLs",
+			content: `Visit [OpenAI](https://openai.com) or https://anthropic.com
+More at [GitHub](https://github.com)`,
+			wantURLs: []string{"https://openai.com", "https://anthropic.com", "https://github.com"},
+		},
+		{
+			name: "duplicate URLs",
+			content: `[Link1](https://example.com)
+[Link2](https://example.com)
+https://example.com`,
+			wantURLs: []string{"https://example.com"},
+		},
+		{
+			name:     "no URLs",
+			content:  `# Just a title\nSome plain text with no links.`,
+			wantURLs: []string{},
+		},
+		{
+			name: "ignore relative links",
+			content: `[Relative](/path/to/page)
+[Anchor](#section)
+[Absolute](https://example.com)`,
+			wantURLs: []string{"https://example.com"},
+		},
+		{
+			name: "complex markdown document",
+			content: `# Documentation
+
+## Links
+- [Go Documentation](https://golang.org/doc)
+- [Package reference](https://pkg.go.dev)
+
+Visit https://example.com for more info.
+
+## Resources
+Check [this guide](https://github.com/guide) for details.`,
+			wantURLs: []string{"https://golang.org/doc", "https://pkg.go.dev", "https://example.com", "https://github.com/guide"},
+		},
+		{
+			name: "http and https",
+			content: `[HTTP](http://example.com)
+[HTTPS](https://example.com)
+http://test.com
+https://test.org`,
+			wantURLs: []string{"http://example.com", "https://example.com", "http://test.com", "https://test.org"},
+		},
+		{
+			name: "URLs with query parameters and fragments",
+			content: `[Search](https://example.com/search?q=test)
+[Section](https://example.com/page#section)
+https://api.example.com/v1/users?id=123`,
+			wantURLs: []string{"https://example.com/search?q=test", "https://example.com/page#section", "https://api.example.com/v1/users?id=123"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := extractMarkdownLinks(tt.content)
+
+			if len(got) != len(tt.wantURLs) {
+				t.Errorf("extractMarkdownLinks() got %d URLs, want %d\nGot: %v\nWant: %v",
+					len(got), len(tt.wantURLs), got, tt.wantURLs)
+				return
+			}
+
+			for i, wantURL := range tt.wantURLs {
+				if got[i] != wantURL {
+					t.Errorf("extractMarkdownLinks()[%d] = %q, want %q", i, got[i], wantURL)
+				}
+			}
+		})
+	}
+}
+
+func TestMarkdownLinks_Relative(t *testing.T) {
+	content := "# Docs\n" +
+		"\n" +
+		"See [setup](../guide/install.md) and [usage](#usage).\n" +
+		"![](img/arch.png) ![diagram](<img/with space.png> \"Diagram\")\n" +
+		"[titled](notes.md 'Notes') [again](../guide/install.md)\n" +
+		"[web](https://example.com) [proto](//cdn.example.com/x.js) [mail](mailto:a@example.com)\n" +
+		"[empty]() [bare](#)\n"
+
+	var got []Link
+	for _, link := range MarkdownLinks(content, MarkdownOptions{}) {
+		if IsRelativeLink(link.URL) {
+			got = append(got, link)
+		}
+	}
+	want := []Link{
+		{URL: "../guide/install.md", Line: 3, Column: 13},
+		{URL: "#usage", Line: 3, Column: 46},
+		{URL: "img/arch.png", Line: 4, Column: 5},
+		{URL: "img/with space.png", Line: 4, Column: 31},
+		{URL: "notes.md", Line: 5, Column: 10},
+		{URL: "../guide/install.md", Line: 5, Column: 36},
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("MarkdownLinks() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i].URL != want[i].URL || got[i].Line != want[i].Line || got[i].Column != want[i].Column {
+			t.Errorf("MarkdownLinks()[%d] = %q at %d:%d, want %q at %d:%d",
+				i, got[i].URL, got[i].Line, got[i].Column, want[i].URL, want[i].Line, want[i].Column)
+		}
+	}
+}
+
+func TestMarkdownLinks_Positions(t *testing.T) {
+	content := "# Title\n" +
+		"\n" +
+		"See [docs](https://example.com/docs) or https://example.com/bare.\n" +
+		"Café https://example.com/docs\n"
+
+	got := MarkdownLinks(content, MarkdownOptions{})
+	want := []Link{
+		{URL: "https://example.com/docs", Kind: KindLinks, Line: 3, Column: 12},
+		{URL: "https://example.com/bare", Kind: KindLinks, Line: 3, Column: 41},
+		{URL: "https://example.com/docs", Kind: KindLinks, Line: 4, Column: 6},
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("MarkdownLinks() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("MarkdownLinks()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestMarkdownLinks_RawHTML(t *testing.T) {
+	content := "# Title\n" +
+		"\n" +
+		"Built with <img src=\"https://example.com/badge.svg\" alt=\"badge\"> daily.\n" +
+		"\n" +
+		"<p align=\"center\">\n" +
+		"  <a href=\"https://example.com/docs\">Docs</a>\n" +
+		"</p>\n"
+
+	got := MarkdownLinks(content, MarkdownOptions{})
+	want := []Link{
+		{URL: "https://example.com/badge.svg", Element: "img", Attribute: "src", Kind: KindImages, Line: 3, Column: 22},
+		{URL: "https://example.com/docs", Element: "a", Attribute: "href", Kind: KindLinks, Line: 6, Column: 12},
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("MarkdownLinks() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("MarkdownLinks()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestMarkdownLinks_CommonMark(t *testing.T) {
+	content := "See the [guide][docs], [Docs] again and [the API][].\n" +
+		"\n" +
+		"[docs]: https://example.com/guide \"Guide\"\n" +
+		"[the api]: <https://example.com/api>\n" +
+		"\n" +
+		"![logo](img/logo.png 'Logo') [wiki](https://en.wikipedia.org/wiki/Go_(language))\n" +
+		"<https://example.com/auto> and www.example.org, plus (https://example.com/paren).\n" +
+		"[mail](mailto:team@example.com) <team@example.com>\n"
+
+	got := MarkdownLinks(content, MarkdownOptions{})
+	want := []Link{
+		{URL: "https://example.com/guide", Kind: KindLinks, Line: 3, Column: 9},
+		{URL: "https://example.com/api", Kind: KindLinks, Line: 4, Column: 13},
+		{URL: "img/logo.png", Kind: KindImages, Line: 6, Column: 9},
+		{URL: "https://en.wikipedia.org/wiki/Go_(language)", Kind: KindLinks, Line: 6, Column: 37},
+		{URL: "https://example.com/auto", Kind: KindLinks, Line: 7, Column: 2},
+		{URL: "http://www.example.org", Kind: KindLinks, Line: 7, Column: 32},
+		{URL: "https://example.com/paren", Kind: KindLinks, Line: 7, Column: 55},
+		{URL: "mailto:team@example.com", Kind: KindLinks, Line: 8, Column: 8},
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("MarkdownLinks() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("MarkdownLinks()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestMarkdownLinks_Code(t *testing.T) {
+	content := "Run `curl https://example.com/span` first.\n" +
+		"\n" +
+		"```sh\n" +
+		"curl https://example.com/fence\n" +
+		"```\n" +
+		"\n" +
+		"    wget https://example.com/indented\n" +
+		"\n" +
+		"Then open https://example.com/text.\n"
+
+	tests := []struct {
+		name string
+		opts MarkdownOptions
+		want []Link
+	}{
+		{
+			name: "code skipped by default",
+			want: []Link{
+				{URL: "https://example.com/text", Kind: KindLinks, Line: 9, Column: 11},
+			},
+		},
+		{
+			name: "code included",
+			opts: MarkdownOptions{IncludeCode: true},
+			want: []Link{
+				{URL: "https://example.com/span", Kind: KindLinks, Line: 1, Column: 11},
+				{URL: "https://example.com/fence", Kind: KindLinks, Line: 4, Column: 6},
+				{URL: "https://example.com/indented", Kind: KindLinks, Line: 7, Column: 10},
+				{URL: "https://example.com/text", Kind: KindLinks, Line: 9, Column: 11},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := MarkdownLinks(content, tt.opts)
+			if len(got) != len(tt.want) {
+				t.Fatalf("MarkdownLinks() = %+v, want %+v", got, tt.want)
+			}
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Errorf("MarkdownLinks()[%d] = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestIsRelativeLink(t *testing.T) {
+	tests := []struct {
+		link string
+		want bool
+	}{
+		{"../guide/install.md", true},
+		{"img/logo.png", true},
+		{"#usage", true},
+		{"/docs/index.md", true},
+		{"https://example.com", false},
+		{"//cdn.example.com/x.js", false},
+		{"mailto:team@example.com", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsRelativeLink(tt.link); got != tt.want {
+			t.Errorf("IsRelativeLink(%q) = %v, want %v", tt.link, got, tt.want)
+		}
+	}
+}
+
+func TestMarkdownLinks_Suppressed(t *testing.T) {
+	content := "# Links\n" +
+		"\n" +
+		"<!-- linkchecker-disable-next-line intranet only -->\n" +
+		"See https://intranet.example.com/wiki for details.\n" +
+		"Then [docs](https://example.com/docs).\n" +
+		"\n" +
+		"<!-- linkchecker-disable -->\n" +
+		"- [one](https://example.com/one)\n" +
+		"- ![two](img/two.png)\n" +
+		"<!-- linkchecker-enable -->\n" +
+		"\n" +
+		"Inline <!-- linkchecker-disable-next-line --> comment\n" +
+		"https://example.com/inline and https://example.com/after\n"
+
+	got := MarkdownLinks(content, MarkdownOptions{})
+	want := map[string]bool{
+		"https://intranet.example.com/wiki": true,
+		"https://example.com/docs":          false,
+		"https://example.com/one":           true,
+		"img/two.png":                       true,
+		"https://example.com/inline":        true,
+		"https://example.com/after":         true,
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("MarkdownLinks() = %+v, want %d links", got, len(want))
+	}
+	for _, link := range got {
+		if link.Suppressed != want[link.URL] {
+			t.Errorf("%s: Suppressed = %v, want %v", link.URL, link.Suppressed, want[link.URL])
+		}
+	}
+
+	if urls := extractMarkdownLinks(content); len(urls) != 1 || urls[0] != "https://example.com/docs" {
+		t.Errorf("extractMarkdownLinks() = %v, want only the unsuppressed link", urls)
+	}
+}
@@ -0,0 +1,339 @@
+// parser.go - URL parsing and HTML link extraction
+
+package linkcheck
+
+import (
+	"bytes"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+)
+
+// isSameDomain checks if two URLs have the same domain
+func isSameDomain(url1, url2 string) bool {
+	u1, err1 := url.Parse(url1)
+	u2, err2 := url.Parse(url2)
+	if err1 != nil || err2 != nil {
+		return false
+	}
+	return u1.Host == u2.Host
+}
+
+// Kinds of links, selected with WithCheck
+const (
+	KindLinks   = "links"   // anchors, iframes, forms and other <link> elements
+	KindImages  = "images"  // images, srcset candidates, posters and icons
+	KindScripts = "scripts" // external scripts
+	KindStyles  = "styles"  // stylesheets
+	KindMedia   = "media"   // audio, video, embeds and objects
+)
+
+// LinkKinds lists every kind accepted by WithCheck
+var LinkKinds = []string{KindLinks, KindImages, KindScripts, KindStyles, KindMedia}
+
+// linkAttrs lists the URL attributes extracted from each element
+var linkAttrs = map[string][]string{
+	"a":      {"href"},
+	"area":   {"href"},
+	"iframe": {"src"},
+	"form":   {"action"},
+	"img":    {"src", "srcset"},
+	"source": {"src", "srcset"},
+	"video":  {"src", "poster"},
+	"audio":  {"src"},
+	"embed":  {"src"},
+	"object": {"data"},
+	"script": {"src"},
+	"link":   {"href"},
+}
+
+// ExtractLinks extracts all links from HTML
+// URLs are resolved against the first <base href>, or baseURL when there is none;
+// with neither, relative URLs are returned as written
+// Each link records the line and column where its attribute value starts
+// Links disabled by an inline directive or inside an element with
+// data-linkchecker-ignore are returned with Suppressed set
+func ExtractLinks(body io.Reader, baseURL *url.URL) ([]Link, error) {
+	content, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+
+	var links []Link
+	lines := newLineIndex(string(content))
+	tokenizer := html.NewTokenizer(bytes.NewReader(content))
+	hasBase := false
+	offset := 0 // start of the next token in content
+	var directives suppressor
+	var ignored ignoredElement
+
+	for {
+		tokenType := tokenizer.Next()
+		tokenStart := offset
+		raw := tokenizer.Raw()
+		offset += len(raw)
+
+		switch tokenType {
+		case html.ErrorToken:
+			err := tokenizer.Err()
+			if err == io.EOF {
+				return links, nil
+			}
+			return links, err
+
+		case html.CommentToken:
+			endLine, _ := lines.Position(offset - 1)
+			directives.comment(string(tokenizer.Text()), endLine)
+
+		case html.EndTagToken:
+			name, _ := tokenizer.TagName()
+			ignored.end(string(name))
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+			_, hasIgnore := tokenAttr(token, ignoreAttr)
+			elementIgnored := ignored.start(token.Data, hasIgnore, tokenType == html.SelfClosingTagToken)
+
+			// only the first <base href> counts
+			if token.Data == "base" && !hasBase {
+				if href, ok := tokenAttr(token, "href"); ok {
+					if parsed, err := url.Parse(strings.TrimSpace(href)); err == nil {
+						if baseURL != nil {
+							parsed = baseURL.ResolveReference(parsed)
+						}
+						baseURL = parsed
+						hasBase = true
+					}
+				}
+				continue
+			}
+
+			attrs, ok := linkAttrs[token.Data]
+			if !ok || skipElement(token) {
+				continue
+			}
+
+			for _, attr := range attrs {
+				value, ok := tokenAttr(token, attr)
+				if !ok {
+					continue
+				}
+				valueStart := tokenStart + attrValueOffset(raw, attr)
+
+				values := []string{value}
+				if attr == "srcset" {
+					values = parseSrcset(value)
+				}
+
+				for _, link := range values {
+					link = strings.TrimSpace(link)
+					linkStart := valueStart
+					if i := strings.Index(value, link); i > 0 {
+						linkStart += i
+					}
+
+					// skip empty, bare "#", and non-http links
+					// same-page "#section" links are kept for fragment checks
+					if link == "" || link == "#" || hasSkippedScheme(link) {
+						continue
+					}
+
+					// resolve relative URLs
+					parsedLink, err := url.Parse(link)
+					if err != nil {
+						continue
+					}
+					if baseURL != nil {
+						link = baseURL.ResolveReference(parsedLink).String()
+					}
+					line, column := lines.Position(linkStart)
+					links = append(links, Link{
+						URL:        link,
+						Element:    token.Data,
+						Attribute:  attr,
+						Kind:       linkKind(token, attr),
+						Line:       line,
+						Column:     column,
+						Suppressed: elementIgnored || directives.suppresses(line),
+					})
+				}
+			}
+		}
+	}
+}
+
+// hasSkippedScheme reports whether a link uses a scheme that is never
+// fetched, such as mailto: or javascript:
+func hasSkippedScheme(link string) bool {
+	return strings.HasPrefix(link, "javascript:") ||
+		strings.HasPrefix(link, "mailto:") ||
+		strings.HasPrefix(link, "tel:") ||
+		strings.HasPrefix(link, "data:")
+}
+
+// attrValueOffset returns the offset of an attribute's value within a raw
+// start tag, or 0 (the start of the tag) when it cannot be found
+func attrValueOffset(raw []byte, key string) int {
+	lower := bytes.ToLower(raw)
+	for i := 0; ; {
+		j := bytes.Index(lower[i:], []byte(key))
+		if j < 0 {
+			return 0
+		}
+		start := i + j
+		i = start + len(key)
+
+		// the key must be a whole attribute name followed by "="
+		if start == 0 || !isHTMLSpace(lower[start-1]) {
+			continue
+		}
+		rest := bytes.TrimLeft(lower[i:], htmlSpace)
+		if len(rest) == 0 || rest[0] != '=' {
+			continue
+		}
+		rest = bytes.TrimLeft(rest[1:], htmlSpace)
+
+		offset := len(raw) - len(rest)
+		if len(rest) > 0 && (rest[0] == '"' || rest[0] == '\'') {
+			offset++
+		}
+		return offset
+	}
+}
+
+// htmlSpace holds the characters HTML treats as whitespace
+const htmlSpace = " \t\n\r\f"
+
+// isHTMLSpace reports whether b is HTML whitespace
+func isHTMLSpace(b byte) bool {
+	return strings.IndexByte(htmlSpace, b) >= 0
+}
+
+// lineIndex converts byte offsets in a document into line and column numbers
+type lineIndex struct {
+	content string
+	starts  []int // offset of the first byte of each line
+}
+
+// newLineIndex indexes the line starts of content
+func newLineIndex(content string) *lineIndex {
+	starts := []int{0}
+	for i := 0; i < len(content); i++ {
+		if content[i] == '\n' {
+			starts = append(starts, i+1)
+		}
+	}
+	return &lineIndex{content: content, starts: starts}
+}
+
+// Position returns the 1-based line and column of a byte offset
+// Columns count characters, as editors do
+func (ix *lineIndex) Position(offset int) (line, column int) {
+	offset = min(max(offset, 0), len(ix.content))
+	line = sort.Search(len(ix.starts), func(i int) bool { return ix.starts[i] > offset })
+	start := ix.starts[line-1]
+	return line, utf8.RuneCountInString(ix.content[start:offset]) + 1
+}
+
+// tokenAttr returns the value of an attribute of a token
+func tokenAttr(token html.Token, key string) (string, bool) {
+	for _, attr := range token.Attr {
+		if attr.Key == key {
+			return attr.Val, true
+		}
+	}
+	return "", false
+}
+
+// skipElement reports whether an element's URL is not worth checking:
+// POST forms, whose endpoints often reject GET, and <link> hints that
+// name an origin rather than a resource
+func skipElement(token html.Token) bool {
+	switch token.Data {
+	case "form":
+		method, _ := tokenAttr(token, "method")
+		return method != "" && !strings.EqualFold(method, "get")
+	case "link":
+		rel, _ := tokenAttr(token, "rel")
+		for _, r := range strings.Fields(strings.ToLower(rel)) {
+			if r == "preconnect" || r == "dns-prefetch" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// linkKind returns the kind of a URL found in an element's attribute
+func linkKind(token html.Token, attr string) string {
+	switch token.Data {
+	case "img":
+		return KindImages
+	case "script":
+		return KindScripts
+	case "source":
+		// srcset belongs to <picture>, src to <audio> and <video>
+		if attr == "srcset" {
+			return KindImages
+		}
+		return KindMedia
+	case "video":
+		if attr == "poster" {
+			return KindImages
+		}
+		return KindMedia
+	case "audio", "embed", "object":
+		return KindMedia
+	case "link":
+		rel, _ := tokenAttr(token, "rel")
+		for _, r := range strings.Fields(strings.ToLower(rel)) {
+			switch r {
+			case "stylesheet":
+				return KindStyles
+			case "icon", "apple-touch-icon", "mask-icon":
+				return KindImages
+			case "modulepreload":
+				return KindScripts
+			}
+		}
+	}
+	return KindLinks
+}
+
+// parseSrcset returns the candidate URLs of a srcset attribute,
+// e.g. "small.jpg 480w, large.jpg 1080w"
+func parseSrcset(srcset string) []string {
+	var urls []string
+	s := srcset
+	for {
+		s = strings.TrimLeft(s, htmlSpace+",")
+		if s == "" {
+			return urls
+		}
+
+		end := strings.IndexAny(s, htmlSpace)
+		if end < 0 {
+			end = len(s)
+		}
+		candidate := s[:end]
+		s = s[end:]
+
+		// a trailing comma ends a candidate without descriptors
+		if trimmed := strings.TrimRight(candidate, ","); trimmed != candidate {
+			urls = append(urls, trimmed)
+			continue
+		}
+		urls = append(urls, candidate)
+
+		// skip the width or density descriptor
+		if i := strings.IndexByte(s, ','); i >= 0 {
+			s = s[i+1:]
+		} else {
+			s = ""
+		}
+	}
+}
@@ -0,0 +1,429 @@
+package linkcheck
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestIsSameDomain(t *testing.T) {
+	tests := []struct {
+		name string
+		url1 string
+		url2 string
+		want bool
+	}{
+		{
+			name: "same domain exact match",
+			url1: "https://example.com/page1",
+			url2: "https://example.com/page2",
+			want: true,
+		},
+		{
+			name: "same domain different paths",
+			url1: "https://example.com/a/b/c",
+			url2: "https://example.com/x/y/z",
+			want: true,
+		},
+		{
+			name: "different domains",
+			url1: "https://example.com",
+			url2: "https://different.com",
+			want: false,
+		},
+		{
+			name: "different subdomains",
+			url1: "https://www.example.com",
+			url2: "https://api.example.com",
+			want: false,
+		},
+		{
+			name: "same domain different schemes",
+			url1: "http://example.com",
+			url2: "https://example.com",
+			want: true,
+		},
+		{
+			name: "same domain different ports",
+			url1: "https://example.com:8080",
+			url2: "https://example.com:9090",
+			want: false,
+		},
+		{
+			name: "invalid url1",
+			url1: "://invalid",
+			url2: "https://example.com",
+			want: false,
+		},
+		{
+			name: "invalid url2",
+			url1: "https://example.com",
+			url2: "://invalid",
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := isSameDomain(tt.url1, tt.url2)
+			if got != tt.want {
+				t.Errorf("isSameDomain(%q, %q) = %v, want %v", tt.url1, tt.url2, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractLinks(t *testing.T) {
+	tests := []struct {
+		name     string
+		html     string
+		baseURL  string
+		wantURLs []string
+		wantErr  bool
+	}{
+		{
+			name: "absolute URLs",
+			html: `<html><body>
+				<a href="https://example.com/page1">Link 1</a>
+				<a href="https://example.com/page2">Link 2</a>
+			</body></html>`,
+			baseURL:  "https://example.com",
+			wantURLs: []string{"https://example.com/page1", "https://example.com/page2"},
+			wantErr:  false,
+		},
+		{
+			name: "relative URLs",
+			html: `<html><body>
+				<a href="/about">About</a>
+				<a href="contact">Contact</a>
+			</body></html>`,
+			baseURL:  "https://example.com",
+			wantURLs: []string{"https://example.com/about", "https://example.com/contact"},
+			wantErr:  false,
+		},
+		{
+			name: "skip bare anchors and javascript",
+			html: `<html><body>
+				<a href="#">Anchor</a>
+				<a href="javascript:void(0)">JS</a>
+				<a href="mailto:test@example.com">Email</a>
+				<a href="/valid">Valid</a>
+			</body></html>`,
+			baseURL:  "https://example.com",
+			wantURLs: []string{"https://example.com/valid"},
+			wantErr:  false,
+		},
+		{
+			name: "same-page fragments resolve against the page",
+			html: `<html><body>
+				<a href="#section">Section</a>
+			</body></html>`,
+			baseURL:  "https://example.com/docs/page",
+			wantURLs: []string{"https://example.com/docs/page#section"},
+			wantErr:  false,
+		},
+		{
+			name: "empty href",
+			html: `<html><body>
+				<a href="">Empty</a>
+				<a>No href</a>
+				<a href="/page">Valid</a>
+			</body></html>`,
+			baseURL:  "https://example.com",
+			wantURLs: []string{"https://example.com/page"},
+			wantErr:  false,
+		},
+		{
+			name: "mixed case and query params",
+			html: `<html><body>
+				<a href="/search?q=test">Search</a>
+				<a href="/page#anchor">Page with anchor</a>
+			</body></html>`,
+			baseURL:  "https://example.com",
+			wantURLs: []string{"https://example.com/search?q=test", "https://example.com/page#anchor"},
+			wantErr:  false,
+		},
+		{
+			name:     "no links",
+			html:     `<html><body><p>No links here</p></body></html>`,
+			baseURL:  "https://example.com",
+			wantURLs: []string{},
+			wantErr:  false,
+		},
+		{
+			name: "relative path resolution",
+			html: `<html><body>
+				<a href="../parent">Parent</a>
+				<a href="./current">Current</a>
+			</body></html>`,
+			baseURL:  "https://example.com/dir/page",
+			wantURLs: []string{"https://example.com/parent", "https://example.com/dir/current"},
+			wantErr:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baseURL, err := url.Parse(tt.baseURL)
+			if err != nil {
+				t.Fatalf("Failed to parse base URL: %v", err)
+			}
+
+			reader := strings.NewReader(tt.html)
+			got, err := ExtractLinks(reader, baseURL)
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ExtractLinks() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if len(got) != len(tt.wantURLs) {
+				t.Errorf("ExtractLinks() got %d links, want %d\nGot: %v\nWant: %v",
+					len(got), len(tt.wantURLs), got, tt.wantURLs)
+				return
+			}
+
+			for i, wantURL := range tt.wantURLs {
+				if got[i].URL != wantURL {
+					t.Errorf("ExtractLinks()[%d] = %q, want %q", i, got[i].URL, wantURL)
+				}
+			}
+		})
+	}
+}
+
+func TestExtractLinks_Resources(t *testing.T) {
+	html := `<html><head>
+		<link rel="stylesheet" href="/style.css">
+		<link rel="icon" href="/favicon.ico">
+		<link rel="preconnect" href="https://fonts.example.com">
+		<link rel="canonical" href="/canonical">
+		<script src="/app.js"></script>
+		<script>inline()</script>
+	</head><body>
+		<img src="/logo.png" srcset="/logo-2x.png 2x, /logo-3x.png 3x">
+		<picture><source srcset="/photo.webp"></picture>
+		<video src="/clip.mp4" poster="/poster.jpg"><source src="/clip.webm"></video>
+		<audio src="/sound.mp3"></audio>
+		<iframe src="/embed"></iframe>
+		<object data="/doc.pdf"></object>
+		<form action="/search"></form>
+		<form action="/login" method="post"></form>
+		<img src="data:image/png;base64,AAAA">
+	</body></html>`
+
+	baseURL, _ := url.Parse("https://example.com/")
+	got, err := ExtractLinks(strings.NewReader(html), baseURL)
+	if err != nil {
+		t.Fatalf("ExtractLinks() error = %v", err)
+	}
+
+	want := []Link{
+		{URL: "https://example.com/style.css", Element: "link", Attribute: "href", Kind: KindStyles},
+		{URL: "https://example.com/favicon.ico", Element: "link", Attribute: "href", Kind: KindImages},
+		{URL: "https://example.com/canonical", Element: "link", Attribute: "href", Kind: KindLinks},
+		{URL: "https://example.com/app.js", Element: "script", Attribute: "src", Kind: KindScripts},
+		{URL: "https://example.com/logo.png", Element: "img", Attribute: "src", Kind: KindImages},
+		{URL: "https://example.com/logo-2x.png", Element: "img", Attribute: "srcset", Kind: KindImages},
+		{URL: "https://example.com/logo-3x.png", Element: "img", Attribute: "srcset", Kind: KindImages},
+		{URL: "https://example.com/photo.webp", Element: "source", Attribute: "srcset", Kind: KindImages},
+		{URL: "https://example.com/clip.mp4", Element: "video", Attribute: "src", Kind: KindMedia},
+		{URL: "https://example.com/poster.jpg", Element: "video", Attribute: "poster", Kind: KindImages},
+		{URL: "https://example.com/clip.webm", Element: "source", Attribute: "src", Kind: KindMedia},
+		{URL: "https://example.com/sound.mp3", Element: "audio", Attribute: "src", Kind: KindMedia},
+		{URL: "https://example.com/embed", Element: "iframe", Attribute: "src", Kind: KindLinks},
+		{URL: "https://example.com/doc.pdf", Element: "object", Attribute: "data", Kind: KindMedia},
+		{URL: "https://example.com/search", Element: "form", Attribute: "action", Kind: KindLinks},
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("ExtractLinks() got %d links, want %d\nGot: %v", len(got), len(want), got)
+	}
+	for i := range want {
+		// positions are covered by TestExtractLinks_Positions
+		got[i].Line, got[i].Column = 0, 0
+		if got[i] != want[i] {
+			t.Errorf("ExtractLinks()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestExtractLinks_BaseHref(t *testing.T) {
+	html := `<html><head>
+		<base href="/docs/v2/">
+		<base href="/ignored/">
+	</head><body>
+		<a href="guide">Guide</a>
+		<img src="../img/logo.png">
+		<a href="https://other.com/page">Absolute</a>
+	</body></html>`
+
+	baseURL, _ := url.Parse("https://example.com/index.html")
+	got, err := ExtractLinks(strings.NewReader(html), baseURL)
+	if err != nil {
+		t.Fatalf("ExtractLinks() error = %v", err)
+	}
+
+	want := []string{
+		"https://example.com/docs/v2/guide",
+		"https://example.com/docs/img/logo.png",
+		"https://other.com/page",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("ExtractLinks() got %d links, want %d\nGot: %v", len(got), len(want), got)
+	}
+	for i, wantURL := range want {
+		if got[i].URL != wantURL {
+			t.Errorf("ExtractLinks()[%d] = %q, want %q", i, got[i].URL, wantURL)
+		}
+	}
+}
+
+func TestExtractLinks_NoBase(t *testing.T) {
+	html := `<a href="guide.html#setup">Guide</a><a href="https://example.com/">Home</a>`
+
+	got, err := ExtractLinks(strings.NewReader(html), nil)
+	if err != nil {
+		t.Fatalf("ExtractLinks() error = %v", err)
+	}
+	if len(got) != 2 || got[0].URL != "guide.html#setup" || got[1].URL != "https://example.com/" {
+		t.Errorf("ExtractLinks() = %+v, want the links as written", got)
+	}
+}
+
+func TestParseSrcset(t *testing.T) {
+	tests := []struct {
+		srcset string
+		want   []string
+	}{
+		{srcset: "image.jpg", want: []string{"image.jpg"}},
+		{srcset: "small.jpg 480w, large.jpg 1080w", want: []string{"small.jpg", "large.jpg"}},
+		{srcset: "a.png 1x,b.png 2x", want: []string{"a.png", "b.png"}},
+		{srcset: "a.png, b.png 2x", want: []string{"a.png", "b.png"}},
+		{srcset: "img.php?size=1,2 1x", want: []string{"img.php?size=1,2"}},
+		{srcset: "  \n a.png 1.5x ,\n b.png  ", want: []string{"a.png", "b.png"}},
+		{srcset: "", want: nil},
+	}
+
+	for _, tt := range tests {
+		got := parseSrcset(tt.srcset)
+		if len(got) != len(tt.want) {
+			t.Errorf("parseSrcset(%q) = %q, want %q", tt.srcset, got, tt.want)
+			continue
+		}
+		for i := range tt.want {
+			if got[i] != tt.want[i] {
+				t.Errorf("parseSrcset(%q) = %q, want %q", tt.srcset, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func BenchmarkIsSameDomain(b *testing.B) {
+	url1 := "https://example.com/page1"
+	url2 := "https://example.com/page2"
+
+	b.ResetTimer()
+	for b.Loop() {
+		isSameDomain(url1, url2)
+	}
+}
+
+func BenchmarkExtractLinks(b *testing.B) {
+	html := `<html><body>
+		<a href="https://example.com/page1">Link 1</a>
+		<a href="https://example.com/page2">Link 2</a>
+		<a href="/relative">Relative</a>
+		<a href="#anchor">Anchor</a>
+		<a href="javascript:void(0)">JS</a>
+	</body></html>`
+
+	baseURL, _ := url.Parse("https://example.com")
+
+	b.ResetTimer()
+	for b.Loop() {
+		reader := strings.NewReader(html)
+		ExtractLinks(reader, baseURL)
+	}
+}
+
+func TestExtractLinks_Positions(t *testing.T) {
+	html := "<html>\n" +
+		"<body>\n" +
+		"  <a href=\"/first\">First</a> <a class=x HREF='/second'>Second</a>\n" +
+		"  <img data-src=\"/lazy.png\" src=/plain.png>\n" +
+		"  <p>café</p><a href=\"/after-unicode\">After</a>\n" +
+		"  <img srcset=\"/a.png 1x, /b.png 2x\">\n" +
+		"</body></html>"
+
+	baseURL, _ := url.Parse("https://example.com")
+	got, err := ExtractLinks(strings.NewReader(html), baseURL)
+	if err != nil {
+		t.Fatalf("ExtractLinks() error = %v", err)
+	}
+
+	want := []struct {
+		path         string
+		line, column int
+	}{
+		{path: "/first", line: 3, column: 12},
+		{path: "/second", line: 3, column: 47},
+		{path: "/plain.png", line: 4, column: 33},
+		{path: "/after-unicode", line: 5, column: 23},
+		{path: "/a.png", line: 6, column: 16},
+		{path: "/b.png", line: 6, column: 27},
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("ExtractLinks() got %d links, want %d\nGot: %+v", len(got), len(want), got)
+	}
+	for i, w := range want {
+		if got[i].URL != "https://example.com"+w.path || got[i].Line != w.line || got[i].Column != w.column {
+			t.Errorf("ExtractLinks()[%d] = %s at %d:%d, want %s at %d:%d",
+				i, got[i].URL, got[i].Line, got[i].Column, w.path, w.line, w.column)
+		}
+	}
+}
+
+func TestExtractLinks_Suppressed(t *testing.T) {
+	html := "<html><body>\n" +
+		"<!-- linkchecker-disable-next-line -->\n" +
+		"<a href=\"/next-line\">Next</a>\n" +
+		"<a href=\"/checked\">Checked</a>\n" +
+		"<!-- linkchecker-disable -->\n" +
+		"<a href=\"/block-1\">1</a>\n" +
+		"<img src=\"/block-2.png\">\n" +
+		"<!-- linkchecker-enable -->\n" +
+		"<a href=\"/element\" data-linkchecker-ignore>Element</a>\n" +
+		"<div data-linkchecker-ignore><p><a href=\"/child\">Child</a></p><div><img src=\"/nested.png\"></div></div>\n" +
+		"<a href=\"/after\">After</a>\n" +
+		"</body></html>"
+
+	baseURL, _ := url.Parse("https://example.com")
+	got, err := ExtractLinks(strings.NewReader(html), baseURL)
+	if err != nil {
+		t.Fatalf("ExtractLinks() error = %v", err)
+	}
+
+	want := map[string]bool{
+		"/next-line":   true,
+		"/checked":     false,
+		"/block-1":     true,
+		"/block-2.png": true,
+		"/element":     true,
+		"/child":       true,
+		"/nested.png":  true,
+		"/after":       false,
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("ExtractLinks() got %d links, want %d\nGot: %+v", len(got), len(want), got)
+	}
+	for _, link := range got {
+		path := strings.TrimPrefix(link.URL, "https://example.com")
+		if link.Suppressed != want[path] {
+			t.Errorf("%s: Suppressed = %v, want %v", path, link.Suppressed, want[path])
+		}
+	}
+}
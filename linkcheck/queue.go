@@ -0,0 +1,98 @@
+// queue.go - Bounded worker pool shared by crawl and direct-check modes
+
+package linkcheck
+
+import (
+	"context"
+	"sync"
+)
+
+const DefaultConcurrency = 10 // default number of concurrent requests
+
+// workQueue runs tasks on a fixed number of workers
+// Tasks may push further tasks, so the queue grows with the crawl frontier
+// while the number of goroutines and open connections stays bounded; the
+// frontier itself is only bounded by the crawl budget, which callers take
+// before pushing.
+// Once ctx is done, queued tasks are dropped and only running tasks finish
+type workQueue struct {
+	ctx     context.Context
+	tasks   []func()
+	pending int // tasks queued or running
+	closed  bool
+	mu      sync.Mutex
+	cond    *sync.Cond
+	workers sync.WaitGroup
+}
+
+// newWorkQueue starts a queue with the given number of workers
+func newWorkQueue(ctx context.Context, workers int) *workQueue {
+	if workers < 1 {
+		workers = 1
+	}
+
+	q := &workQueue{ctx: ctx}
+	q.cond = sync.NewCond(&q.mu)
+
+	q.workers.Add(workers)
+	for range workers {
+		go q.work()
+	}
+	return q
+}
+
+// Push adds a task to the end of the queue
+func (q *workQueue) Push(task func()) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.tasks = append(q.tasks, task)
+	q.pending++
+	q.cond.Broadcast()
+}
+
+// Wait blocks until every task, including tasks pushed by other tasks,
+// has finished, then stops the workers
+func (q *workQueue) Wait() {
+	q.mu.Lock()
+	for q.pending > 0 {
+		q.cond.Wait()
+	}
+	q.closed = true
+	q.cond.Broadcast()
+	q.mu.Unlock()
+
+	q.workers.Wait()
+}
+
+// work runs tasks in FIFO order until the queue is closed
+func (q *workQueue) work() {
+	defer q.workers.Done()
+
+	for {
+		q.mu.Lock()
+		for len(q.tasks) == 0 && !q.closed {
+			q.cond.Wait()
+		}
+		if len(q.tasks) == 0 {
+			q.mu.Unlock()
+			return
+		}
+		task := q.tasks[0]
+		q.tasks[0] = nil
+		q.tasks = q.tasks[1:]
+		q.mu.Unlock()
+
+		// drop the task instead of starting it once the run is stopped
+		if q.ctx.Err() == nil {
+			task()
+		}
+
+		q.mu.Lock()
+		q.pending--
+		if q.pending == 0 {
+			q.cond.Broadcast()
+		}
+		q.mu.Unlock()
+	}
+}
@@ -0,0 +1,108 @@
+package linkcheck
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWorkQueue_RunsAllTasks(t *testing.T) {
+	queue := newWorkQueue(t.Context(), 4)
+	var count int32
+
+	for range 100 {
+		queue.Push(func() {
+			atomic.AddInt32(&count, 1)
+		})
+	}
+	queue.Wait()
+
+	if count != 100 {
+		t.Errorf("Expected 100 tasks to run, got %d", count)
+	}
+}
+
+func TestWorkQueue_NestedTasks(t *testing.T) {
+	queue := newWorkQueue(t.Context(), 2)
+	var count int32
+
+	// each task pushes two children until depth 5: 1+2+4+8+16+32 = 63 tasks
+	var spawn func(depth int)
+	spawn = func(depth int) {
+		atomic.AddInt32(&count, 1)
+		if depth == 5 {
+			return
+		}
+		queue.Push(func() { spawn(depth + 1) })
+		queue.Push(func() { spawn(depth + 1) })
+	}
+
+	queue.Push(func() { spawn(0) })
+	queue.Wait()
+
+	if count != 63 {
+		t.Errorf("Expected 63 tasks to run, got %d", count)
+	}
+}
+
+func TestWorkQueue_WorkerLimit(t *testing.T) {
+	const workers = 3
+	queue := newWorkQueue(t.Context(), workers)
+	var running, maxRunning int32
+
+	for range 30 {
+		queue.Push(func() {
+			n := atomic.AddInt32(&running, 1)
+			for {
+				m := atomic.LoadInt32(&maxRunning)
+				if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			atomic.AddInt32(&running, -1)
+		})
+	}
+	queue.Wait()
+
+	if maxRunning > workers {
+		t.Errorf("Expected at most %d concurrent tasks, got %d", workers, maxRunning)
+	}
+}
+
+func TestWorkQueue_EmptyWait(t *testing.T) {
+	queue := newWorkQueue(t.Context(), DefaultConcurrency)
+
+	done := make(chan struct{})
+	go func() {
+		queue.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Wait() on an empty queue did not return")
+	}
+}
+
+func TestWorkQueue_Cancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(t.Context())
+	queue := newWorkQueue(ctx, 1)
+	var count int32
+
+	// the first task stops the run, so the queued tasks are dropped
+	queue.Push(func() {
+		atomic.AddInt32(&count, 1)
+		cancel()
+	})
+	for range 10 {
+		queue.Push(func() { atomic.AddInt32(&count, 1) })
+	}
+	queue.Wait()
+
+	if count != 1 {
+		t.Errorf("Expected only the running task to finish, got %d", count)
+	}
+}
@@ -0,0 +1,197 @@
+// ratelimit.go - Per-host rate limiting and politeness
+
+package linkcheck
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// RateLimits configures how hard a single host may be hit
+type RateLimits struct {
+	Default         float64            // requests per second for hosts without their own rate, 0 means unlimited
+	Hosts           map[string]float64 // requests per second by host name or host:port
+	HostConcurrency int                // maximum in-flight requests per host, 0 means unlimited
+}
+
+// Set parses a -rate value: "2.5" sets the default rate, "host=2.5" sets a per-host rate
+func (l *RateLimits) Set(value string) error {
+	host, perSecond, found := strings.Cut(value, "=")
+	if !found {
+		perSecond, host = host, ""
+	}
+
+	rps, err := strconv.ParseFloat(strings.TrimSpace(perSecond), 64)
+	if err != nil || rps < 0 {
+		return fmt.Errorf("invalid rate %q, expected req/s or host=req/s", value)
+	}
+
+	host = strings.ToLower(strings.TrimSpace(host))
+	if host == "" {
+		if found {
+			return fmt.Errorf("invalid rate %q, missing host", value)
+		}
+		l.Default = rps
+		return nil
+	}
+
+	if l.Hosts == nil {
+		l.Hosts = make(map[string]float64)
+	}
+	l.Hosts[host] = rps
+	return nil
+}
+
+// String formats the limits as accepted by Set
+func (l *RateLimits) String() string {
+	return strings.Join(l.values(), ",")
+}
+
+// Get returns the limits as a list of values accepted by Set
+func (l *RateLimits) Get() any {
+	return l.values()
+}
+
+// values returns each limit formatted as accepted by Set, hosts in sorted order
+func (l *RateLimits) values() []string {
+	if l == nil {
+		return nil
+	}
+
+	var values []string
+	if l.Default > 0 {
+		values = append(values, strconv.FormatFloat(l.Default, 'g', -1, 64))
+	}
+	for _, host := range slices.Sorted(maps.Keys(l.Hosts)) {
+		values = append(values, host+"="+strconv.FormatFloat(l.Hosts[host], 'g', -1, 64))
+	}
+	return values
+}
+
+// rateFor returns the requests per second allowed for a host
+func (l *RateLimits) rateFor(hostPort, hostname string) float64 {
+	if rps, ok := l.Hosts[strings.ToLower(hostPort)]; ok {
+		return rps
+	}
+	if rps, ok := l.Hosts[strings.ToLower(hostname)]; ok {
+		return rps
+	}
+	return l.Default
+}
+
+// hostLimiter holds the token bucket and concurrency slots of one host
+type hostLimiter struct {
+	limiter *rate.Limiter // nil when the host is not rate limited
+	slots   chan struct{} // nil when host concurrency is unlimited
+}
+
+// politeTransport applies RateLimits to every request before handing it to next
+// Timeout bounds each request once it has been admitted, so time spent waiting
+// for a token or a free slot does not count against it
+type politeTransport struct {
+	next    http.RoundTripper
+	limits  RateLimits
+	timeout time.Duration
+	hosts   map[string]*hostLimiter
+	mu      sync.Mutex
+}
+
+// newPoliteTransport wraps next with per-host limits and a per-request timeout
+func newPoliteTransport(next http.RoundTripper, limits RateLimits, timeout time.Duration) *politeTransport {
+	return &politeTransport{
+		next:    next,
+		limits:  limits,
+		timeout: timeout,
+		hosts:   make(map[string]*hostLimiter),
+	}
+}
+
+// RoundTrip waits for the host's rate and concurrency limits, then sends the request
+func (t *politeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	host := t.host(req.URL)
+
+	// hold a concurrency slot until the response body is closed
+	release := func() {}
+	if host.slots != nil {
+		select {
+		case host.slots <- struct{}{}:
+			release = sync.OnceFunc(func() { <-host.slots })
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	if host.limiter != nil {
+		if err := host.limiter.Wait(ctx); err != nil {
+			release()
+			return nil, err
+		}
+	}
+
+	cancel := context.CancelFunc(func() {})
+	if t.timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, t.timeout)
+		req = req.WithContext(ctx)
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		cancel()
+		release()
+		return nil, err
+	}
+
+	resp.Body = &releaseBody{ReadCloser: resp.Body, release: func() {
+		cancel()
+		release()
+	}}
+	return resp, nil
+}
+
+// host returns the limiter for a URL's host, creating it on first use
+func (t *politeTransport) host(u *url.URL) *hostLimiter {
+	hostPort := strings.ToLower(u.Host)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if h, ok := t.hosts[hostPort]; ok {
+		return h
+	}
+
+	h := &hostLimiter{}
+	if rps := t.limits.rateFor(hostPort, u.Hostname()); rps > 0 {
+		h.limiter = rate.NewLimiter(rate.Limit(rps), 1)
+	}
+	if t.limits.HostConcurrency > 0 {
+		h.slots = make(chan struct{}, t.limits.HostConcurrency)
+	}
+	t.hosts[hostPort] = h
+	return h
+}
+
+// releaseBody runs release once the response body is closed
+type releaseBody struct {
+	io.ReadCloser
+	release func()
+	once    sync.Once
+}
+
+// Close closes the body and releases the request's slot
+func (b *releaseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
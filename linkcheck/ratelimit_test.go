@@ -0,0 +1,196 @@
+package linkcheck
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRateLimits_Set(t *testing.T) {
+	tests := []struct {
+		name        string
+		values      []string
+		wantDefault float64
+		wantHosts   map[string]float64
+		wantErr     bool
+	}{
+		{
+			name:        "default rate",
+			values:      []string{"2.5"},
+			wantDefault: 2.5,
+		},
+		{
+			name:      "per-host rate",
+			values:    []string{"Example.com=0.5"},
+			wantHosts: map[string]float64{"example.com": 0.5},
+		},
+		{
+			name:        "default and per-host rates",
+			values:      []string{"10", "example.com=1", "slow.example.com:8080=0.2"},
+			wantDefault: 10,
+			wantHosts:   map[string]float64{"example.com": 1, "slow.example.com:8080": 0.2},
+		},
+		{
+			name:    "invalid number",
+			values:  []string{"fast"},
+			wantErr: true,
+		},
+		{
+			name:    "negative rate",
+			values:  []string{"example.com=-1"},
+			wantErr: true,
+		},
+		{
+			name:    "missing host",
+			values:  []string{"=1"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var limits RateLimits
+			var err error
+			for _, value := range tt.values {
+				if err = limits.Set(value); err != nil {
+					break
+				}
+			}
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Set() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if limits.Default != tt.wantDefault {
+				t.Errorf("Default = %v, want %v", limits.Default, tt.wantDefault)
+			}
+			if len(limits.Hosts) != len(tt.wantHosts) {
+				t.Errorf("Hosts = %v, want %v", limits.Hosts, tt.wantHosts)
+			}
+			for host, want := range tt.wantHosts {
+				if limits.Hosts[host] != want {
+					t.Errorf("Hosts[%q] = %v, want %v", host, limits.Hosts[host], want)
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimits_RateFor(t *testing.T) {
+	limits := RateLimits{
+		Default: 5,
+		Hosts:   map[string]float64{"example.com": 1, "example.com:8080": 2, "fast.example.com": 0},
+	}
+
+	tests := []struct {
+		rawURL string
+		want   float64
+	}{
+		{rawURL: "https://example.com/page", want: 1},
+		{rawURL: "https://example.com:8080/page", want: 2},
+		{rawURL: "https://example.com:9090/page", want: 1},
+		{rawURL: "https://fast.example.com/", want: 0},
+		{rawURL: "https://other.com/", want: 5},
+	}
+
+	for _, tt := range tests {
+		u, _ := url.Parse(tt.rawURL)
+		if got := limits.rateFor(u.Host, u.Hostname()); got != tt.want {
+			t.Errorf("rateFor(%q) = %v, want %v", tt.rawURL, got, tt.want)
+		}
+	}
+}
+
+func TestPoliteTransport_RateLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// 20 req/s with a burst of 1 spaces requests 50ms apart
+	limits := RateLimits{Default: 20}
+	fetcher := &HTTPFetcher{Client: &http.Client{Transport: newPoliteTransport(http.DefaultTransport, limits, 5*time.Second)}}
+
+	start := time.Now()
+	for range 5 {
+		if result := checkURL(t.Context(), fetcher, checkOptions{}, server.URL); result.Error != nil {
+			t.Fatalf("checkURL() error = %v", result.Error)
+		}
+	}
+	elapsed := time.Since(start)
+
+	if elapsed < 180*time.Millisecond {
+		t.Errorf("5 requests at 20 req/s took %v, expected at least 200ms", elapsed)
+	}
+}
+
+func TestPoliteTransport_HostConcurrency(t *testing.T) {
+	var inFlight, maxInFlight int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			m := atomic.LoadInt32(&maxInFlight)
+			if n <= m || atomic.CompareAndSwapInt32(&maxInFlight, m, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	limits := RateLimits{HostConcurrency: 2}
+	fetcher := &HTTPFetcher{Client: &http.Client{Transport: newPoliteTransport(http.DefaultTransport, limits, 5*time.Second)}}
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Go(func() {
+			checkURL(t.Context(), fetcher, checkOptions{}, server.URL)
+		})
+	}
+	wg.Wait()
+
+	if got := atomic.LoadInt32(&maxInFlight); got > 2 {
+		t.Errorf("Expected at most 2 concurrent requests per host, got %d", got)
+	}
+}
+
+func TestPoliteTransport_Timeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	fetcher := &HTTPFetcher{Client: &http.Client{Transport: newPoliteTransport(http.DefaultTransport, RateLimits{}, 10*time.Millisecond)}}
+	if result := checkURL(t.Context(), fetcher, checkOptions{}, server.URL); result.Error == nil {
+		t.Error("checkURL() expected timeout error, got nil")
+	}
+}
+
+func TestPoliteTransport_WaitNotCountedAsTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// the third request waits ~200ms for a token, longer than the 100ms timeout
+	limits := RateLimits{Default: 10}
+	fetcher := &HTTPFetcher{Client: &http.Client{Transport: newPoliteTransport(http.DefaultTransport, limits, 100*time.Millisecond)}}
+
+	results := checkURLs(t.Context(), fetcher, []string{server.URL, server.URL, server.URL}, checkOptions{Concurrency: 3})
+	for _, result := range results {
+		if result.Error != nil {
+			t.Errorf("Unexpected error while waiting for rate limit: %v", result.Error)
+		}
+	}
+}
@@ -0,0 +1,274 @@
+// robots.go - robots.txt fetching and matching for crawl mode
+
+package linkcheck
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	maxRobotsSize      = 512 * 1024 // robots.txt bytes read, as recommended by RFC 9309
+	maxRobotsRedirects = 5          // redirects followed to robots.txt, as required by RFC 9309
+)
+
+// robotsRule is a single Allow or Disallow line
+type robotsRule struct {
+	allow   bool
+	pattern string
+}
+
+// robotsRules holds the rules of the group that applies to our user agent
+type robotsRules struct {
+	rules      []robotsRule
+	crawlDelay time.Duration
+	err        error // why robots.txt could not be fetched, everything is disallowed then
+}
+
+// parseRobots parses robots.txt and keeps the group that best matches userAgent
+// A group naming our product token wins over the "*" group
+func parseRobots(r io.Reader, userAgent string) *robotsRules {
+	token := robotsToken(userAgent)
+
+	type group struct {
+		agents     []string
+		rules      []robotsRule
+		crawlDelay time.Duration
+	}
+	var groups []*group
+	var current *group
+	inAgents := false
+
+	scanner := bufio.NewScanner(io.LimitReader(r, maxRobotsSize))
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			// consecutive user-agent lines share one group
+			if !inAgents {
+				current = &group{}
+				groups = append(groups, current)
+			}
+			current.agents = append(current.agents, strings.ToLower(value))
+			inAgents = true
+		case "allow", "disallow":
+			inAgents = false
+			if current == nil || (key == "disallow" && value == "") {
+				continue
+			}
+			current.rules = append(current.rules, robotsRule{allow: key == "allow", pattern: value})
+		case "crawl-delay":
+			inAgents = false
+			if current == nil {
+				continue
+			}
+			if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds > 0 {
+				current.crawlDelay = time.Duration(seconds * float64(time.Second))
+			}
+		default:
+			inAgents = false
+		}
+	}
+
+	// merge every group naming our token, fall back to "*" groups
+	var specific, wildcard robotsRules
+	foundSpecific := false
+	for _, g := range groups {
+		if token != "" && slices.Contains(g.agents, token) {
+			foundSpecific = true
+			specific.rules = append(specific.rules, g.rules...)
+			specific.crawlDelay = max(specific.crawlDelay, g.crawlDelay)
+		} else if slices.Contains(g.agents, "*") {
+			wildcard.rules = append(wildcard.rules, g.rules...)
+			wildcard.crawlDelay = max(wildcard.crawlDelay, g.crawlDelay)
+		}
+	}
+
+	if foundSpecific {
+		return &specific
+	}
+	return &wildcard
+}
+
+// Allowed reports whether a URL path (with query) may be fetched
+// The longest matching rule wins, and Allow wins a tie
+func (r *robotsRules) Allowed(path string) bool {
+	if path == "" {
+		path = "/"
+	}
+
+	allowed := true
+	bestLen := -1
+	for _, rule := range r.rules {
+		if !robotsMatch(rule.pattern, path) {
+			continue
+		}
+		if len(rule.pattern) > bestLen || (len(rule.pattern) == bestLen && rule.allow) {
+			bestLen = len(rule.pattern)
+			allowed = rule.allow
+		}
+	}
+	return allowed
+}
+
+// robotsMatch matches a path prefix against a rule supporting "*" and a trailing "$"
+func robotsMatch(pattern, path string) bool {
+	for len(pattern) > 0 {
+		switch {
+		case pattern[0] == '*':
+			rest := pattern[1:]
+			for i := 0; i <= len(path); i++ {
+				if robotsMatch(rest, path[i:]) {
+					return true
+				}
+			}
+			return false
+		case pattern == "$":
+			return path == ""
+		case path == "" || pattern[0] != path[0]:
+			return false
+		}
+		pattern, path = pattern[1:], path[1:]
+	}
+	return true
+}
+
+// robotsToken returns the product token of a user agent, e.g. "linkchecker" for "linkchecker/1.0"
+func robotsToken(userAgent string) string {
+	token, _, _ := strings.Cut(userAgent, "/")
+	token, _, _ = strings.Cut(token, " ")
+	return strings.ToLower(strings.TrimSpace(token))
+}
+
+// robotsCache fetches robots.txt once per host and answers Allowed queries
+type robotsCache struct {
+	fetcher   Fetcher
+	userAgent string
+	hosts     map[string]*robotsHost
+	mu        sync.Mutex
+}
+
+// robotsHost is the cached robots.txt state of a single scheme and host
+type robotsHost struct {
+	once      sync.Once
+	rules     *robotsRules
+	lastFetch time.Time
+	mu        sync.Mutex
+}
+
+// newRobotsCache creates an empty cache that fetches with fetcher
+func newRobotsCache(fetcher Fetcher, userAgent string) *robotsCache {
+	return &robotsCache{
+		fetcher:   fetcher,
+		userAgent: userAgent,
+		hosts:     make(map[string]*robotsHost),
+	}
+}
+
+// Allowed reports whether robots.txt lets us fetch targetURL
+func (c *robotsCache) Allowed(ctx context.Context, targetURL string) bool {
+	u, err := url.Parse(targetURL)
+	if err != nil {
+		return true
+	}
+	return c.host(ctx, u).rules.Allowed(u.RequestURI())
+}
+
+// Err returns why robots.txt of targetURL's host could not be fetched, or nil
+func (c *robotsCache) Err(ctx context.Context, targetURL string) error {
+	u, err := url.Parse(targetURL)
+	if err != nil {
+		return nil
+	}
+	return c.host(ctx, u).rules.err
+}
+
+// Wait blocks until the host's Crawl-delay has passed since the previous fetch
+// It returns false if ctx is done first
+func (c *robotsCache) Wait(ctx context.Context, targetURL string) bool {
+	u, err := url.Parse(targetURL)
+	if err != nil {
+		return true
+	}
+
+	host := c.host(ctx, u)
+	if host.rules.crawlDelay <= 0 {
+		return true
+	}
+
+	host.mu.Lock()
+	defer host.mu.Unlock()
+
+	if wait := time.Until(host.lastFetch.Add(host.rules.crawlDelay)); wait > 0 && !sleep(ctx, wait) {
+		return false
+	}
+	host.lastFetch = time.Now()
+	return true
+}
+
+// host returns the cached state for a URL's host, fetching robots.txt on first use
+func (c *robotsCache) host(ctx context.Context, u *url.URL) *robotsHost {
+	key := u.Scheme + "://" + u.Host
+
+	c.mu.Lock()
+	host, ok := c.hosts[key]
+	if !ok {
+		host = &robotsHost{}
+		c.hosts[key] = host
+	}
+	c.mu.Unlock()
+
+	host.once.Do(func() {
+		host.rules = c.fetch(ctx, key+"/robots.txt")
+	})
+	return host
+}
+
+// fetch downloads and parses robots.txt, following up to 5 redirects
+// As RFC 9309 requires, a missing robots.txt (4xx, or too many redirects)
+// allows everything, while an unreachable one (5xx or a network error)
+// disallows everything
+func (c *robotsCache) fetch(ctx context.Context, robotsURL string) *robotsRules {
+	result := LinkResult{URL: robotsURL}
+	resp, err := fetchURL(ctx, c.fetcher, checkOptions{MaxRedirects: maxRobotsRedirects}, http.MethodGet, &result)
+	switch {
+	case errors.Is(err, errTooManyRedirects) || errors.Is(err, errRedirectLoop):
+		return &robotsRules{}
+	case errors.Is(err, ErrNotRecorded):
+		// a replayed site without a recorded robots.txt has none
+		return &robotsRules{}
+	case err != nil:
+		return disallowAll(fmt.Errorf("robots.txt unreachable: %w", err))
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.Status >= 500:
+		return disallowAll(fmt.Errorf("robots.txt unreachable: status %d", resp.Status))
+	case resp.Status < 200 || resp.Status >= 300:
+		return &robotsRules{}
+	}
+	return parseRobots(resp.Body, c.userAgent)
+}
+
+// disallowAll returns rules that disallow every path because of err
+func disallowAll(err error) *robotsRules {
+	return &robotsRules{rules: []robotsRule{{allow: false, pattern: "/"}}, err: err}
+}
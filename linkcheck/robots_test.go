@@ -0,0 +1,186 @@
+package linkcheck
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseRobots_Allowed(t *testing.T) {
+	robots := `# example robots.txt
+User-agent: *
+Disallow: /private
+Allow: /private/public
+Disallow: /*.pdf$
+Disallow: /search?
+
+User-agent: linkchecker
+User-agent: otherbot
+Disallow: /no-checkers
+`
+
+	tests := []struct {
+		name      string
+		userAgent string
+		path      string
+		want      bool
+	}{
+		{name: "root allowed", userAgent: "somebot/2.0", path: "/", want: true},
+		{name: "disallowed prefix", userAgent: "somebot/2.0", path: "/private/page", want: false},
+		{name: "longer allow wins", userAgent: "somebot/2.0", path: "/private/public/page", want: true},
+		{name: "wildcard with end anchor", userAgent: "somebot/2.0", path: "/files/report.pdf", want: false},
+		{name: "end anchor does not match longer path", userAgent: "somebot/2.0", path: "/files/report.pdf.html", want: true},
+		{name: "query string", userAgent: "somebot/2.0", path: "/search?q=go", want: false},
+		{name: "specific group replaces wildcard", userAgent: "linkchecker/1.0", path: "/private/page", want: true},
+		{name: "specific group rule", userAgent: "linkchecker/1.0", path: "/no-checkers/page", want: false},
+		{name: "user agent is case-insensitive", userAgent: "LinkChecker/1.0", path: "/no-checkers", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := parseRobots(strings.NewReader(robots), tt.userAgent)
+			if got := rules.Allowed(tt.path); got != tt.want {
+				t.Errorf("Allowed(%q) for %q = %v, want %v", tt.path, tt.userAgent, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRobots_CrawlDelay(t *testing.T) {
+	tests := []struct {
+		name   string
+		robots string
+		want   time.Duration
+	}{
+		{name: "no delay", robots: "User-agent: *\nDisallow: /x\n", want: 0},
+		{name: "whole seconds", robots: "User-agent: *\nCrawl-delay: 2\n", want: 2 * time.Second},
+		{name: "fractional seconds", robots: "User-agent: *\nCrawl-delay: 0.5\n", want: 500 * time.Millisecond},
+		{name: "other agent only", robots: "User-agent: otherbot\nCrawl-delay: 10\n", want: 0},
+		{name: "invalid value", robots: "User-agent: *\nCrawl-delay: soon\n", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := parseRobots(strings.NewReader(tt.robots), DefaultUserAgent)
+			if rules.crawlDelay != tt.want {
+				t.Errorf("crawlDelay = %v, want %v", rules.crawlDelay, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRobots_EmptyDisallow(t *testing.T) {
+	rules := parseRobots(strings.NewReader("User-agent: *\nDisallow:\n"), DefaultUserAgent)
+	if !rules.Allowed("/anything") {
+		t.Error("Empty Disallow should allow everything")
+	}
+}
+
+func TestRobotsCache_FetchesOncePerHost(t *testing.T) {
+	var fetches int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			atomic.AddInt32(&fetches, 1)
+			fmt.Fprint(w, "User-agent: *\nDisallow: /private\n")
+		}
+	}))
+	defer server.Close()
+
+	fetcher := &HTTPFetcher{Client: &http.Client{Timeout: 5 * time.Second}}
+	cache := newRobotsCache(fetcher, DefaultUserAgent)
+
+	if !cache.Allowed(t.Context(), server.URL+"/public") {
+		t.Error("Expected /public to be allowed")
+	}
+	if cache.Allowed(t.Context(), server.URL+"/private/page") {
+		t.Error("Expected /private/page to be disallowed")
+	}
+
+	if fetches != 1 {
+		t.Errorf("robots.txt fetched %d times, want 1", fetches)
+	}
+}
+
+func TestRobotsCache_RedirectedRobots(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/robots.txt":
+			http.Redirect(w, r, "/moved/robots.txt", http.StatusMovedPermanently)
+		case "/moved/robots.txt":
+			fmt.Fprint(w, "User-agent: *\nDisallow: /private\n")
+		}
+	}))
+	defer server.Close()
+
+	fetcher := &HTTPFetcher{Client: &http.Client{Timeout: 5 * time.Second}}
+	cache := newRobotsCache(fetcher, DefaultUserAgent)
+
+	if cache.Allowed(t.Context(), server.URL+"/private/page") {
+		t.Error("Expected /private/page disallowed by the redirected robots.txt")
+	}
+	if !cache.Allowed(t.Context(), server.URL+"/public") {
+		t.Error("Expected /public to be allowed")
+	}
+}
+
+func TestRobotsCache_MissingRobots(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	fetcher := &HTTPFetcher{Client: &http.Client{Timeout: 5 * time.Second}}
+	cache := newRobotsCache(fetcher, DefaultUserAgent)
+
+	if !cache.Allowed(t.Context(), server.URL+"/anything") {
+		t.Error("Missing robots.txt should allow everything")
+	}
+}
+
+func TestRobotsCache_UnreachableRobots(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	fetcher := &HTTPFetcher{Client: &http.Client{Timeout: 5 * time.Second}}
+	cache := newRobotsCache(fetcher, DefaultUserAgent)
+
+	if cache.Allowed(t.Context(), server.URL+"/anything") {
+		t.Error("robots.txt answering 503 should disallow everything")
+	}
+	if err := cache.Err(t.Context(), server.URL+"/anything"); err == nil {
+		t.Error("Err() = nil, want why robots.txt could not be fetched")
+	}
+
+	// a host that cannot be reached at all is treated the same way
+	closed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	closed.Close()
+	if cache.Allowed(t.Context(), closed.URL+"/anything") {
+		t.Error("Unreachable robots.txt should disallow everything")
+	}
+}
+
+func TestRobotsCache_CrawlDelay(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "User-agent: *\nCrawl-delay: 0.05\n")
+	}))
+	defer server.Close()
+
+	fetcher := &HTTPFetcher{Client: &http.Client{Timeout: 5 * time.Second}}
+	cache := newRobotsCache(fetcher, DefaultUserAgent)
+
+	start := time.Now()
+	for range 3 {
+		cache.Wait(t.Context(), server.URL+"/page")
+	}
+
+	// first fetch is immediate, the next two wait 50ms each
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("3 fetches with 50ms Crawl-delay took %v, expected at least 100ms", elapsed)
+	}
+}
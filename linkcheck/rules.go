@@ -0,0 +1,148 @@
+// rules.go - URL patterns excluding and including links
+
+package linkcheck
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+const regexPrefix = "re:" // marks a pattern as a regular expression instead of a glob
+
+// Pattern is a compiled URL pattern, see CompilePattern
+type Pattern struct {
+	text string // the pattern as written
+	re   *regexp.Regexp
+}
+
+// String returns the pattern as written
+func (p Pattern) String() string {
+	return p.text
+}
+
+// Match reports whether the pattern matches a URL
+func (p Pattern) Match(targetURL string) bool {
+	return p.re.MatchString(targetURL)
+}
+
+// CompilePattern compiles a glob, where "*" matches any run of characters and
+// "?" a single one, anchored to the whole URL, or a regular expression
+// prefixed with "re:", which matches anywhere in the URL
+func CompilePattern(pattern string) (Pattern, error) {
+	if expr, ok := strings.CutPrefix(pattern, regexPrefix); ok {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return Pattern{}, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+		return Pattern{text: pattern, re: re}, nil
+	}
+
+	var b strings.Builder
+	b.WriteString("^")
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return Pattern{text: pattern, re: regexp.MustCompile(b.String())}, nil
+}
+
+// PatternList is a flag that can be repeated to collect URL patterns
+// It does not split on commas, which regular expressions use
+type PatternList []Pattern
+
+// String joins the patterns as written
+func (l *PatternList) String() string {
+	return strings.Join(l.patterns(), " ")
+}
+
+// Get returns the patterns as written
+func (l *PatternList) Get() any {
+	return l.patterns()
+}
+
+// Set compiles and appends a pattern
+func (l *PatternList) Set(value string) error {
+	p, err := CompilePattern(value)
+	if err != nil {
+		return err
+	}
+	*l = append(*l, p)
+	return nil
+}
+
+// patterns returns the patterns as written
+func (l *PatternList) patterns() []string {
+	if l == nil {
+		return nil
+	}
+	patterns := make([]string, len(*l))
+	for i, p := range *l {
+		patterns[i] = p.text
+	}
+	return patterns
+}
+
+// match returns the first pattern matching a URL
+func (l PatternList) match(targetURL string) (Pattern, bool) {
+	for _, p := range l {
+		if p.re.MatchString(targetURL) {
+			return p, true
+		}
+	}
+	return Pattern{}, false
+}
+
+// ReadPatterns adds the patterns of a file, one per line, to a list
+// Blank lines and lines starting with "#" are skipped
+func ReadPatterns(file string, list *PatternList) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if err := list.Set(line); err != nil {
+			return fmt.Errorf("%s:%d: %w", file, lineNumber, err)
+		}
+	}
+	return scanner.Err()
+}
+
+// URLRules decides which URLs are checked
+// A URL matching an include pattern is always checked, one matching an
+// exclude pattern is not. When there are include patterns but no exclude
+// patterns, only the included URLs are checked
+type URLRules struct {
+	Include PatternList
+	Exclude PatternList
+}
+
+// excludes reports whether a URL is excluded and describes the rule that excluded it
+func (r URLRules) excludes(targetURL string) (string, bool) {
+	if _, ok := r.Include.match(targetURL); ok {
+		return "", false
+	}
+	if p, ok := r.Exclude.match(targetURL); ok {
+		return "exclude " + p.text, true
+	}
+	if len(r.Include) > 0 && len(r.Exclude) == 0 {
+		return "no include pattern matched", true
+	}
+	return "", false
+}
@@ -0,0 +1,130 @@
+package linkcheck
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCompilePattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		url     string
+		want    bool
+	}{
+		{pattern: "http://localhost*", url: "http://localhost:8080/docs", want: true},
+		{pattern: "http://localhost*", url: "https://localhost/", want: false},
+		{pattern: "*://intranet.example.com/*", url: "https://intranet.example.com/wiki", want: true},
+		{pattern: "*://intranet.example.com/*", url: "https://example.com/?next=intranet.example.com", want: false},
+		{pattern: "https://example.com/v?/api", url: "https://example.com/v2/api", want: true},
+		{pattern: "https://example.com/a.b", url: "https://example.com/aXb", want: false},
+		{pattern: "https://example.com/", url: "https://example.com/page", want: false},
+		{pattern: `re:^https?://(www\.)?linkedin\.com/`, url: "https://www.linkedin.com/in/x", want: true},
+		{pattern: `re:\.pdf$`, url: "https://example.com/files/report.pdf", want: true},
+		{pattern: `re:\.pdf$`, url: "https://example.com/files/report.pdf.html", want: false},
+		{pattern: "re:x{1,2}", url: "https://example.com/xx", want: true},
+	}
+
+	for _, tt := range tests {
+		p, err := CompilePattern(tt.pattern)
+		if err != nil {
+			t.Fatalf("CompilePattern(%q) error = %v", tt.pattern, err)
+		}
+		if got := p.re.MatchString(tt.url); got != tt.want {
+			t.Errorf("pattern %q matches %q = %v, want %v", tt.pattern, tt.url, got, tt.want)
+		}
+	}
+
+	if _, err := CompilePattern("re:("); err == nil {
+		t.Error("CompilePattern(\"re:(\") error = nil, want an error")
+	}
+}
+
+func TestURLRules_Excludes(t *testing.T) {
+	newRules := func(exclude, include []string) URLRules {
+		var r URLRules
+		for _, p := range exclude {
+			r.Exclude.Set(p)
+		}
+		for _, p := range include {
+			r.Include.Set(p)
+		}
+		return r
+	}
+
+	tests := []struct {
+		name     string
+		rules    URLRules
+		url      string
+		wantRule string
+		want     bool
+	}{
+		{name: "no rules", url: "https://example.com/", want: false},
+		{
+			name:     "excluded",
+			rules:    newRules([]string{"*example.com*"}, nil),
+			url:      "https://example.com/",
+			wantRule: "exclude *example.com*",
+			want:     true,
+		},
+		{
+			name:  "not excluded",
+			rules: newRules([]string{"*example.com*"}, nil),
+			url:   "https://golang.org/",
+			want:  false,
+		},
+		{
+			name:  "include overrides exclude",
+			rules: newRules([]string{"https://example.com/*"}, []string{"https://example.com/docs/*"}),
+			url:   "https://example.com/docs/install",
+			want:  false,
+		},
+		{
+			name:  "neither matches with both kinds",
+			rules: newRules([]string{"https://example.com/*"}, []string{"https://example.com/docs/*"}),
+			url:   "https://golang.org/",
+			want:  false,
+		},
+		{
+			name:     "include only",
+			rules:    newRules(nil, []string{"https://example.com/*"}),
+			url:      "https://golang.org/",
+			wantRule: "no include pattern matched",
+			want:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, got := tt.rules.excludes(tt.url)
+			if got != tt.want || rule != tt.wantRule {
+				t.Errorf("excludes(%q) = %q, %v, want %q, %v", tt.url, rule, got, tt.wantRule, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadPatterns(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "exclude.txt")
+	content := "# hosts that block bots\nhttp://localhost*\n\n  re:linkedin\\.com  \n"
+	if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var list PatternList
+	if err := ReadPatterns(file, &list); err != nil {
+		t.Fatalf("ReadPatterns() error = %v", err)
+	}
+	if got := list.patterns(); !slices.Equal(got, []string{"http://localhost*", `re:linkedin\.com`}) {
+		t.Errorf("patterns = %q, want both patterns", got)
+	}
+
+	if err := os.WriteFile(file, []byte("ok\nre:(\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ReadPatterns(file, &list); err == nil || !strings.Contains(err.Error(), "exclude.txt:2") {
+		t.Errorf("ReadPatterns() error = %v, want the file and line of the bad pattern", err)
+	}
+}
@@ -0,0 +1,205 @@
+// site.go - Checking a static site built to a local directory
+
+package linkcheck
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// site holds the state shared by every file of a directory check
+// Links under base are resolved against root on disk, every other link is
+// checked over HTTP
+type site struct {
+	ctx       context.Context
+	fetcher   Fetcher
+	root      string
+	base      *url.URL
+	budget    *crawlBudget
+	opts      checkOptions
+	visited   *safeUrlMap
+	locations *locationSet
+	anchors   *fileAnchorCache
+	queue     *workQueue
+	results   []LinkResult
+	resultsMu sync.Mutex
+}
+
+// checkSite checks the links of every file under root that has an extractor,
+// such as HTML and Markdown files, as if the directory were published at baseURL
+func checkSite(ctx context.Context, fetcher Fetcher, root string, baseURL *url.URL, budget *crawlBudget, opts checkOptions) ([]LinkResult, error) {
+	base := *baseURL
+	if !strings.HasSuffix(base.Path, "/") {
+		base.Path += "/"
+	}
+
+	locations := newLocationSet()
+	s := &site{
+		ctx:       ctx,
+		fetcher:   fetcher,
+		root:      root,
+		base:      &base,
+		budget:    budget,
+		opts:      opts.withLocations(locations),
+		visited:   &safeUrlMap{visited: make(map[string]bool)},
+		locations: locations,
+		anchors:   newFileAnchorCache(),
+		queue:     newWorkQueue(ctx, opts.Concurrency),
+	}
+
+	err := filepath.WalkDir(root, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		if extractor := budget.opts.fileExtractor(file); extractor != nil {
+			s.queue.Push(func() { s.checkFile(file, extractor) })
+		}
+		return nil
+	})
+
+	// let queued files finish even if the walk failed part way
+	s.queue.Wait()
+	if err != nil {
+		return nil, err
+	}
+
+	s.locations.Attach(s.results)
+	return s.results, nil
+}
+
+// checkFile checks the links of a single file, extracted with extractor
+func (s *site) checkFile(file string, extractor Extractor) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		s.addResult(LinkResult{URL: file, Error: err, Severity: SeverityError})
+		return
+	}
+
+	links, err := extractor.Extract(bytes.NewReader(content), s.pageURL(file))
+	if err != nil {
+		return
+	}
+
+	for _, link := range links {
+		if hasSkippedScheme(link.URL) || !s.budget.opts.checks(link.Kind) {
+			continue
+		}
+		if link.Suppressed {
+			s.addResult(link.SuppressedResult(file))
+			continue
+		}
+
+		// every occurrence is recorded, but each URL is only checked once
+		s.locations.Add(link.URL, link.At(file))
+		if s.visited.Visit(link.URL) {
+			continue
+		}
+		if result, ok := s.opts.excluded(link.URL); ok {
+			s.addResult(result)
+			continue
+		}
+
+		u, err := url.Parse(link.URL)
+		if err != nil || !s.isLocal(u) {
+			if s.budget.TakeExternal() {
+				s.queue.Push(func() { s.checkExternal(link.URL) })
+			}
+			continue
+		}
+		s.addResult(s.checkLocal(link.URL, u))
+	}
+}
+
+// checkLocal checks a link under the base URL against the files on disk
+// Local targets are reported with the status a static file server would send
+func (s *site) checkLocal(link string, u *url.URL) LinkResult {
+	result := LinkResult{URL: link, Status: http.StatusOK, Severity: SeverityOK}
+
+	target, ok := s.resolve(u)
+	if !ok {
+		result.Status = http.StatusNotFound
+		result.Severity = SeverityError
+		return result
+	}
+
+	// a fragment must name an anchor of the target document
+	_, fragment := splitFragment(link)
+	if fragment != "" {
+		if anchors, ok := s.anchors.Anchors(target); ok && !anchors[fragment] {
+			result.Fragment = fragment
+			result.Severity = SeverityError
+		}
+	}
+	return result
+}
+
+// checkExternal checks a link outside the base URL over HTTP
+func (s *site) checkExternal(link string) {
+	s.addResult(checkURL(s.ctx, s.fetcher, s.opts, link))
+}
+
+// isLocal reports whether a URL is served from the directory
+func (s *site) isLocal(u *url.URL) bool {
+	return strings.EqualFold(u.Scheme, s.base.Scheme) &&
+		strings.EqualFold(u.Host, s.base.Host) &&
+		strings.HasPrefix(u.Path+"/", s.base.Path)
+}
+
+// pageURL returns the URL a file is published at
+func (s *site) pageURL(file string) *url.URL {
+	rel, err := filepath.Rel(s.root, file)
+	if err != nil {
+		rel = filepath.Base(file)
+	}
+	return s.base.ResolveReference(&url.URL{Path: filepath.ToSlash(rel)})
+}
+
+// resolve maps a local URL to the file a static server would send for it:
+// the file itself, "index.html" for directories, or "<path>.html" for pretty URLs
+func (s *site) resolve(u *url.URL) (string, bool) {
+	rel := strings.TrimPrefix(u.Path, strings.TrimSuffix(s.base.Path, "/"))
+	name := filepath.Join(s.root, filepath.FromSlash(path.Clean("/"+rel)))
+
+	candidates := []string{filepath.Join(name, "index.html")}
+	if rel != "" && !strings.HasSuffix(rel, "/") {
+		candidates = []string{name, name + ".html", filepath.Join(name, "index.html")}
+	}
+
+	for _, candidate := range candidates {
+		if info, err := os.Stat(candidate); err == nil && info.Mode().IsRegular() {
+			return candidate, true
+		}
+	}
+	return "", false
+}
+
+// addResult records a finished check
+// A check stopped with the run is not finished, so it is left out
+func (s *site) addResult(result LinkResult) {
+	if errors.Is(result.Error, errStopped) {
+		return
+	}
+	s.resultsMu.Lock()
+	s.results = append(s.results, result)
+	s.resultsMu.Unlock()
+
+	s.opts.emit(result)
+}
+
+// isHTMLPath reports whether a path names an HTML file
+func isHTMLPath(p string) bool {
+	ext := strings.ToLower(filepath.Ext(p))
+	return ext == ".html" || ext == ".htm"
+}
@@ -0,0 +1,266 @@
+package linkcheck
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeSite writes files relative to a temporary directory and returns it
+func writeSite(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestCheckSite(t *testing.T) {
+	externalServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer externalServer.Close()
+
+	root := writeSite(t, map[string]string{
+		"index.html": fmt.Sprintf(`<html><body>
+			<a href="/guide/">Directory index</a>
+			<a href="/about">Pretty URL</a>
+			<a href="https://docs.example.com/guide/install.html">Absolute internal</a>
+			<a href="guide/install.html#setup">Fragment</a>
+			<a href="guide/install.html#nowhere">Missing fragment</a>
+			<a href="/missing.html">Missing</a>
+			<img src="/img/logo.png">
+			<a href="%[1]s/ok">External</a>
+			<a href="%[1]s/missing">External missing</a>
+		</body></html>`, externalServer.URL),
+		"about.html":         `<html><body><a href="/">Home</a></body></html>`,
+		"guide/index.html":   `<html><body><a href="install.html">Install</a></body></html>`,
+		"guide/install.html": `<html><body><h2 id="setup">Setup</h2><a href="../img/missing.png">Img</a></body></html>`,
+		"img/logo.png":       "png",
+	})
+
+	baseURL, _ := url.Parse("https://docs.example.com")
+	fetcher := &HTTPFetcher{Client: &http.Client{Timeout: 5 * time.Second}}
+	results, err := checkSite(t.Context(), fetcher, root, baseURL, newCrawlBudget(crawlOptions{}), checkOptions{Concurrency: DefaultConcurrency})
+	if err != nil {
+		t.Fatalf("checkSite() error = %v", err)
+	}
+
+	byURL := make(map[string]LinkResult)
+	for _, result := range results {
+		byURL[result.URL] = result
+	}
+
+	tests := []struct {
+		url        string
+		wantStatus int
+		wantBroken bool
+	}{
+		{url: "https://docs.example.com/guide/", wantStatus: 200},
+		{url: "https://docs.example.com/about", wantStatus: 200},
+		{url: "https://docs.example.com/guide/install.html", wantStatus: 200},
+		{url: "https://docs.example.com/guide/install.html#setup", wantStatus: 200},
+		{url: "https://docs.example.com/guide/install.html#nowhere", wantStatus: 200, wantBroken: true},
+		{url: "https://docs.example.com/missing.html", wantStatus: 404, wantBroken: true},
+		{url: "https://docs.example.com/img/logo.png", wantStatus: 200},
+		{url: "https://docs.example.com/img/missing.png", wantStatus: 404, wantBroken: true},
+		{url: externalServer.URL + "/ok", wantStatus: 200},
+		{url: externalServer.URL + "/missing", wantStatus: 404, wantBroken: true},
+	}
+
+	for _, tt := range tests {
+		result, ok := byURL[tt.url]
+		if !ok {
+			t.Errorf("No result for %s", tt.url)
+			continue
+		}
+		if result.Status != tt.wantStatus || result.Broken() != tt.wantBroken {
+			t.Errorf("%s: status %d broken %v, want %d %v", tt.url, result.Status, result.Broken(), tt.wantStatus, tt.wantBroken)
+		}
+	}
+
+	if source := byURL["https://docs.example.com/missing.html"].SourceURL; source != filepath.Join(root, "index.html") {
+		t.Errorf("SourceURL = %q, want the file path", source)
+	}
+	if fragment := byURL["https://docs.example.com/guide/install.html#nowhere"].Fragment; fragment != "nowhere" {
+		t.Errorf("Fragment = %q, want %q", fragment, "nowhere")
+	}
+}
+
+func TestCheckSite_WithoutBaseURL(t *testing.T) {
+	root := writeSite(t, map[string]string{
+		"index.html": `<html><body>
+			<a href="/docs/">Docs</a>
+			<a href="/gone">Gone</a>
+		</body></html>`,
+		"docs/index.html": `<html><body></body></html>`,
+	})
+
+	fetcher := &HTTPFetcher{Client: &http.Client{Timeout: 5 * time.Second}}
+	results, err := checkSite(t.Context(), fetcher, root, &url.URL{Scheme: "file", Path: "/"}, newCrawlBudget(crawlOptions{}), checkOptions{Concurrency: DefaultConcurrency})
+	if err != nil {
+		t.Fatalf("checkSite() error = %v", err)
+	}
+
+	broken := 0
+	for _, result := range results {
+		if result.Broken() {
+			broken++
+			if result.URL != "file:///gone" {
+				t.Errorf("Unexpected broken link %s", result.URL)
+			}
+		}
+	}
+	if len(results) != 2 || broken != 1 {
+		t.Errorf("Got %d results with %d broken, want 2 with 1 broken", len(results), broken)
+	}
+}
+
+func TestCheckSite_BasePath(t *testing.T) {
+	root := writeSite(t, map[string]string{
+		"index.html": `<html><body>
+			<a href="/v2/page.html">Under base path</a>
+			<a href="/v2/">Base index</a>
+		</body></html>`,
+		"page.html": `<html></html>`,
+	})
+
+	baseURL, _ := url.Parse("https://docs.example.com/v2")
+	fetcher := &HTTPFetcher{Client: &http.Client{Timeout: 5 * time.Second}}
+	results, err := checkSite(t.Context(), fetcher, root, baseURL, newCrawlBudget(crawlOptions{}), checkOptions{Concurrency: DefaultConcurrency})
+	if err != nil {
+		t.Fatalf("checkSite() error = %v", err)
+	}
+
+	for _, result := range results {
+		if result.Broken() {
+			t.Errorf("Unexpected broken link %s (%d)", result.URL, result.Status)
+		}
+	}
+	if len(results) != 2 {
+		t.Errorf("Got %d results, want 2", len(results))
+	}
+}
+
+func TestSite_Resolve(t *testing.T) {
+	root := writeSite(t, map[string]string{
+		"index.html":      "",
+		"about.html":      "",
+		"blog/index.html": "",
+		"style.css":       "",
+	})
+	s := &site{root: root, base: &url.URL{Scheme: "https", Host: "example.com", Path: "/"}}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "/", want: "index.html"},
+		{path: "", want: "index.html"},
+		{path: "/about", want: "about.html"},
+		{path: "/about.html", want: "about.html"},
+		{path: "/blog", want: "blog/index.html"},
+		{path: "/blog/", want: "blog/index.html"},
+		{path: "/style.css", want: "style.css"},
+		{path: "/missing", want: ""},
+		{path: "/about/", want: ""},
+	}
+
+	for _, tt := range tests {
+		got, ok := s.resolve(&url.URL{Path: tt.path})
+		want := ""
+		if tt.want != "" {
+			want = filepath.Join(root, filepath.FromSlash(tt.want))
+		}
+		if got != want || ok != (tt.want != "") {
+			t.Errorf("resolve(%q) = %q, %v, want %q", tt.path, got, ok, want)
+		}
+	}
+}
+
+func TestCheckSite_Locations(t *testing.T) {
+	root := writeSite(t, map[string]string{
+		"index.html": `<a href="/missing.html">Missing</a>`,
+		"about.html": `<p>About</p><a href="/missing.html">Missing</a> <a href="/missing.html">Again</a>`,
+	})
+
+	fetcher := &HTTPFetcher{Client: &http.Client{Timeout: 5 * time.Second}}
+	results, err := checkSite(t.Context(), fetcher, root, &url.URL{Scheme: "file", Path: "/"}, newCrawlBudget(crawlOptions{}), checkOptions{Concurrency: DefaultConcurrency})
+	if err != nil {
+		t.Fatalf("checkSite() error = %v", err)
+	}
+
+	if len(results) != 1 {
+		t.Fatalf("Got %d results, want 1 for the shared link", len(results))
+	}
+	if got := len(results[0].Locations); got != 3 {
+		t.Errorf("Got %d locations, want 3: %v", got, results[0].Locations)
+	}
+}
+
+func TestCheckSite_Markdown(t *testing.T) {
+	// Markdown files are checked like HTML files, while robots.txt is not
+	// read as a list of URLs
+	root := writeSite(t, map[string]string{
+		"docs/README.md": "[Guide](guide.md) [Missing](missing.md) [me](mailto:a@example.com)\n",
+		"docs/guide.md":  "# Guide\n",
+		"robots.txt":     "User-agent: *\nDisallow:\n",
+	})
+
+	fetcher := &HTTPFetcher{Client: &http.Client{Timeout: 5 * time.Second}}
+	results, err := checkSite(t.Context(), fetcher, root, &url.URL{Scheme: "file", Path: "/"}, newCrawlBudget(crawlOptions{}), checkOptions{Concurrency: DefaultConcurrency})
+	if err != nil {
+		t.Fatalf("checkSite() error = %v", err)
+	}
+
+	byURL := make(map[string]LinkResult)
+	for _, result := range results {
+		byURL[result.URL] = result
+	}
+	if len(byURL) != 2 {
+		t.Fatalf("Expected the two Markdown links, got %+v", results)
+	}
+	if got := byURL["file:///docs/guide.md"]; got.Broken() {
+		t.Errorf("guide.md = %+v, want found on disk", got)
+	}
+	if got := byURL["file:///docs/missing.md"]; !got.Broken() || got.Status != http.StatusNotFound {
+		t.Errorf("missing.md = %+v, want 404", got)
+	}
+}
+
+func TestCheckSite_WithExtractors(t *testing.T) {
+	root := writeSite(t, map[string]string{
+		"feed.rss": "<rss></rss>",
+	})
+	extractors := NewExtractors()
+	extractors.Register(ExtractorFunc(func(body io.Reader, baseURL *url.URL) ([]Link, error) {
+		return []Link{{URL: resolveLink(baseURL, "/missing.html"), Kind: KindLinks}}, nil
+	}), ".rss")
+
+	checker, err := New(WithExtractors(extractors))
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := checker.CheckSite(t.Context(), root, nil)
+	if err != nil {
+		t.Fatalf("CheckSite() error = %v", err)
+	}
+	if len(report.Results) != 1 || report.Results[0].URL != "file:///missing.html" || !report.Results[0].Broken() {
+		t.Errorf("Results = %+v, want the link of the registered extractor", report.Results)
+	}
+}
@@ -0,0 +1,168 @@
+// status.go - Classifying results by status code with per-URL rules
+
+package linkcheck
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Severity classifies a checked result
+type Severity string
+
+const (
+	SeverityOK      Severity = "ok"      // the link works
+	SeverityInfo    Severity = "info"    // the link works, with a detail worth knowing such as a temporary redirect
+	SeverityWarning Severity = "warning" // the link works but should be looked at
+	SeverityError   Severity = "error"   // the link is broken
+)
+
+// severities lists the levels from least to most severe
+var severities = []Severity{SeverityOK, SeverityInfo, SeverityWarning, SeverityError}
+
+// AtLeast reports whether s is as severe as threshold or more
+// Skipped results have no severity and are below every threshold
+func (s Severity) AtLeast(threshold Severity) bool {
+	return s != "" && slices.Index(severities, s) >= slices.Index(severities, threshold)
+}
+
+// StatusRange is an inclusive range of status codes
+type StatusRange struct {
+	Min, Max int
+}
+
+// ParseStatusRange parses "403", "200-299" or "2xx"
+func ParseStatusRange(s string) (StatusRange, error) {
+	s = strings.TrimSpace(s)
+	invalid := fmt.Errorf("invalid status %q, expected a code such as 403, a range such as 200-299, or 2xx", s)
+
+	if len(s) == 3 && strings.HasSuffix(strings.ToLower(s), "xx") {
+		class, err := strconv.Atoi(s[:1])
+		if err != nil || class < 1 {
+			return StatusRange{}, invalid
+		}
+		return StatusRange{class * 100, class*100 + 99}, nil
+	}
+
+	first, last, isRange := strings.Cut(s, "-")
+	if !isRange {
+		last = first
+	}
+	low, err1 := strconv.Atoi(strings.TrimSpace(first))
+	high, err2 := strconv.Atoi(strings.TrimSpace(last))
+	if err1 != nil || err2 != nil || low < 100 || high > 999 || low > high {
+		return StatusRange{}, invalid
+	}
+	return StatusRange{low, high}, nil
+}
+
+// ParseStatusRanges parses a list of codes and ranges
+func ParseStatusRanges(codes []string) ([]StatusRange, error) {
+	var ranges []StatusRange
+	for _, code := range codes {
+		sr, err := ParseStatusRange(code)
+		if err != nil {
+			return nil, err
+		}
+		ranges = append(ranges, sr)
+	}
+	return ranges, nil
+}
+
+// contains reports whether a status code is in the range
+func (r StatusRange) contains(status int) bool {
+	return status >= r.Min && status <= r.Max
+}
+
+// String formats the range as accepted by ParseStatusRange
+func (r StatusRange) String() string {
+	if r.Min == r.Max {
+		return strconv.Itoa(r.Min)
+	}
+	return fmt.Sprintf("%d-%d", r.Min, r.Max)
+}
+
+// StatusRule sets the status codes that are ok or only a warning for the
+// URLs of a host or matching a pattern; every other status is an error
+type StatusRule struct {
+	Host    string   // host name, also matching its subdomains; empty when URL is set
+	URL     *Pattern // glob or "re:" pattern; nil when Host is set
+	OK      []StatusRange
+	Warning []StatusRange
+}
+
+// matches reports whether the rule applies to a URL
+func (r StatusRule) matches(targetURL string) bool {
+	if r.URL != nil {
+		return r.URL.re.MatchString(targetURL)
+	}
+
+	u, err := url.Parse(targetURL)
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	return host == r.Host || strings.HasSuffix(host, "."+r.Host)
+}
+
+// severity classifies a status code under the rule
+func (r StatusRule) severity(status int) Severity {
+	for _, sr := range r.OK {
+		if sr.contains(status) {
+			return SeverityOK
+		}
+	}
+	for _, sr := range r.Warning {
+		if sr.contains(status) {
+			return SeverityWarning
+		}
+	}
+	return SeverityError
+}
+
+// String describes what the rule matches, e.g. "host linkedin.com"
+func (r StatusRule) String() string {
+	if r.URL != nil {
+		return "url " + r.URL.text
+	}
+	return "host " + r.Host
+}
+
+// classify sets the severity of a result from its status code, using the
+// first status rule matching its URL. Without a rule 4xx and 5xx are errors.
+// A working link with a warning, such as a permanent redirect, or slower than
+// SlowThreshold is a warning, and one reached through a temporary redirect is info
+func (o checkOptions) classify(result *LinkResult) {
+	result.Severity = SeverityOK
+	if result.Status >= 400 {
+		result.Severity = SeverityError
+	}
+
+	for _, rule := range o.StatusRules {
+		if rule.matches(result.URL) {
+			result.Severity = rule.severity(result.Status)
+			if result.Severity == SeverityWarning && result.Warning == "" {
+				result.Warning = fmt.Sprintf("status %d is a warning for %s", result.Status, rule)
+			}
+			break
+		}
+	}
+
+	if result.Severity != SeverityOK {
+		return
+	}
+	if o.SlowThreshold > 0 && result.Duration > o.SlowThreshold && result.Warning == "" {
+		result.Warning = fmt.Sprintf("slow response, took %s", result.Duration.Round(time.Millisecond))
+	}
+	switch {
+	case result.Warning != "":
+		result.Severity = SeverityWarning
+	case len(result.Redirects) > 0:
+		result.Severity = SeverityInfo
+		result.Info = "temporary redirect to " + result.Redirects[len(result.Redirects)-1].Location
+	}
+}
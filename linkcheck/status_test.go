@@ -0,0 +1,120 @@
+package linkcheck
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseStatusRange(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    StatusRange
+		wantErr bool
+	}{
+		{in: "403", want: StatusRange{403, 403}},
+		{in: "200-299", want: StatusRange{200, 299}},
+		{in: " 401 - 403 ", want: StatusRange{401, 403}},
+		{in: "2xx", want: StatusRange{200, 299}},
+		{in: "5XX", want: StatusRange{500, 599}},
+		{in: "999", want: StatusRange{999, 999}},
+		{in: "0xx", wantErr: true},
+		{in: "42", wantErr: true},
+		{in: "299-200", wantErr: true},
+		{in: "ok", wantErr: true},
+		{in: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseStatusRange(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseStatusRange(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("ParseStatusRange(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestCheckOptions_Classify(t *testing.T) {
+	gone, _ := CompilePattern("https://example.com/removed*")
+	opts := checkOptions{SlowThreshold: 2 * time.Second, StatusRules: []StatusRule{
+		{Host: "linkedin.com", OK: []StatusRange{{200, 299}, {999, 999}}, Warning: []StatusRange{{403, 403}}},
+		{URL: &gone, OK: []StatusRange{{404, 404}, {410, 410}}},
+		{Host: "members.example.com", OK: []StatusRange{{200, 299}, {401, 401}}},
+	}}
+
+	tests := []struct {
+		name        string
+		result      LinkResult
+		want        Severity
+		wantWarning string
+	}{
+		{name: "default ok", result: LinkResult{URL: "https://example.com/", Status: 200}, want: SeverityOK},
+		{name: "default error", result: LinkResult{URL: "https://example.com/", Status: 404}, want: SeverityError},
+		{
+			name:        "permanent redirect",
+			result:      LinkResult{URL: "https://example.com/", Status: 200, Warning: "permanent redirect, update the link"},
+			want:        SeverityWarning,
+			wantWarning: "permanent redirect, update the link",
+		},
+		{name: "host rule ok", result: LinkResult{URL: "https://www.linkedin.com/in/x", Status: 999}, want: SeverityOK},
+		{
+			name:        "host rule warning",
+			result:      LinkResult{URL: "https://linkedin.com/company/x", Status: 403},
+			want:        SeverityWarning,
+			wantWarning: "status 403 is a warning for host linkedin.com",
+		},
+		{name: "host rule error", result: LinkResult{URL: "https://linkedin.com/x", Status: 500}, want: SeverityError},
+		{name: "other host", result: LinkResult{URL: "https://notlinkedin.com/", Status: 999}, want: SeverityError},
+		{name: "login wall", result: LinkResult{URL: "https://members.example.com/", Status: 401}, want: SeverityOK},
+		{name: "expected 404", result: LinkResult{URL: "https://example.com/removed/page", Status: 404}, want: SeverityOK},
+		{name: "unexpected 200", result: LinkResult{URL: "https://example.com/removed/page", Status: 200}, want: SeverityError},
+		{
+			name: "temporary redirect",
+			result: LinkResult{URL: "https://example.com/", Status: 200, Redirects: []Redirect{
+				{URL: "https://example.com/", Status: 302, Location: "https://example.com/home"},
+			}},
+			want: SeverityInfo,
+		},
+		{
+			name:        "slow response",
+			result:      LinkResult{URL: "https://example.com/", Status: 200, Duration: 3 * time.Second},
+			want:        SeverityWarning,
+			wantWarning: "slow response, took 3s",
+		},
+		{name: "slow error", result: LinkResult{URL: "https://example.com/", Status: 500, Duration: 3 * time.Second}, want: SeverityError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.result
+			opts.classify(&result)
+			if result.Severity != tt.want || result.Warning != tt.wantWarning {
+				t.Errorf("classify() = %q, %q, want %q, %q", result.Severity, result.Warning, tt.want, tt.wantWarning)
+			}
+		})
+	}
+}
+
+func TestSeverity_AtLeast(t *testing.T) {
+	tests := []struct {
+		severity  Severity
+		threshold Severity
+		want      bool
+	}{
+		{SeverityError, SeverityError, true},
+		{SeverityWarning, SeverityError, false},
+		{SeverityError, SeverityWarning, true},
+		{SeverityWarning, SeverityWarning, true},
+		{SeverityInfo, SeverityWarning, false},
+		{SeverityOK, SeverityWarning, false},
+		{"", SeverityWarning, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.severity.AtLeast(tt.threshold); got != tt.want {
+			t.Errorf("%q.AtLeast(%q) = %v, want %v", tt.severity, tt.threshold, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,24 @@
+// transport.go - HTTP transport helpers
+
+package linkcheck
+
+import "net/http"
+
+const DefaultUserAgent = "linkchecker/1.0" // User-Agent sent with every request
+
+// headerTransport adds fixed headers to every request that does not set them
+type headerTransport struct {
+	next   http.RoundTripper
+	header http.Header
+}
+
+// RoundTrip sets the configured headers and sends the request
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for key, values := range t.header {
+		if req.Header.Get(key) == "" {
+			req.Header[key] = values
+		}
+	}
+	return t.next.RoundTrip(req)
+}
@@ -0,0 +1,42 @@
+package linkcheck
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHeaderTransport(t *testing.T) {
+	var gotUserAgent, gotAccept string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUserAgent = r.Header.Get("User-Agent")
+		gotAccept = r.Header.Get("Accept")
+	}))
+	defer server.Close()
+
+	client := &http.Client{
+		Timeout: 5 * time.Second,
+		Transport: &headerTransport{
+			next:   http.DefaultTransport,
+			header: http.Header{"User-Agent": {DefaultUserAgent}, "Accept": {"text/html"}},
+		},
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	req.Header.Set("Accept", "*/*")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if gotUserAgent != DefaultUserAgent {
+		t.Errorf("User-Agent = %q, want %q", gotUserAgent, DefaultUserAgent)
+	}
+
+	// headers set on the request take precedence
+	if gotAccept != "*/*" {
+		t.Errorf("Accept = %q, want %q", gotAccept, "*/*")
+	}
+}
@@ -0,0 +1,356 @@
+// types.go - Data structures for link checking
+
+package linkcheck
+
+import (
+	"cmp"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const DefaultMaxDepth = 2 // default maximum crawl depth
+
+// skip reasons recorded in LinkResult.Skipped
+const (
+	SkipRobots     = "robots"     // disallowed by robots.txt
+	SkipExcluded   = "excluded"   // excluded by URLRules, see LinkResult.Rule
+	SkipSuppressed = "suppressed" // disabled by an inline directive in its source document
+)
+
+// checkOptions controls how URLs are fetched in both crawl and direct-check mode
+type checkOptions struct {
+	Concurrency   int           // maximum number of concurrent requests
+	Retries       int           // extra attempts after a network error, 429 or 5xx
+	RetryBackoff  time.Duration // delay before the first retry, doubled for each further retry
+	GetOnlyHosts  []string      // hosts that are always checked with GET instead of HEAD
+	MaxRedirects  int           // redirects followed before giving up, 0 means DefaultMaxRedirects
+	Rules         URLRules      // URLs reported as excluded instead of checked
+	StatusRules   []StatusRule  // status codes that are ok or a warning per host or URL pattern
+	SlowThreshold time.Duration // responses slower than this are warnings, 0 means never
+
+	OnResult func(LinkResult) // called with each result as it completes, see WithOnResult
+}
+
+// emit passes a finished result to OnResult
+func (o checkOptions) emit(result LinkResult) {
+	if o.OnResult != nil {
+		o.OnResult(result)
+	}
+}
+
+// withLocations returns options whose OnResult sees the locations recorded in
+// s so far; the report attaches every location once the run has finished
+func (o checkOptions) withLocations(s *locationSet) checkOptions {
+	if onResult := o.OnResult; onResult != nil {
+		o.OnResult = func(result LinkResult) {
+			s.attach(&result)
+			onResult(result)
+		}
+	}
+	return o
+}
+
+// excluded returns the result for a URL excluded by the rules, ok is false
+// when the URL should be checked
+func (o checkOptions) excluded(targetURL string) (result LinkResult, ok bool) {
+	rule, ok := o.Rules.excludes(targetURL)
+	return LinkResult{URL: targetURL, Skipped: SkipExcluded, Rule: rule}, ok
+}
+
+// isGetOnly reports whether a URL's host must be checked with GET
+func (o checkOptions) isGetOnly(targetURL string) bool {
+	u, err := url.Parse(targetURL)
+	if err != nil {
+		return false
+	}
+	for _, host := range o.GetOnlyHosts {
+		if strings.EqualFold(host, u.Host) || strings.EqualFold(host, u.Hostname()) {
+			return true
+		}
+	}
+	return false
+}
+
+// crawlOptions limits how much of a site a crawl is allowed to fetch
+type crawlOptions struct {
+	MaxDepth     int      // maximum link depth followed from the start URL
+	MaxPages     int      // maximum same-domain pages fetched, 0 means unlimited
+	MaxExternal  int      // maximum external links checked, 0 means unlimited
+	UserAgent    string   // user agent matched against robots.txt groups
+	IgnoreRobots bool     // crawl pages even if robots.txt disallows them
+	Check        []string // kinds of links checked, see LinkKinds; empty means all
+
+	Extractors *Extractors // parsers of the pages followed, nil means DefaultExtractors
+}
+
+// extractor returns the extractor of a fetched page
+// A page served without a Content-Type whose extension is not registered is
+// read as HTML
+func (o crawlOptions) extractor(resp *Response) Extractor {
+	extractors := cmp.Or(o.Extractors, DefaultExtractors)
+	contentType := resp.Header.Get("Content-Type")
+	if extractor := extractors.Lookup(contentType, resp.URL.Path); extractor != nil || contentType != "" {
+		return extractor
+	}
+	return extractors.Lookup("text/html", "")
+}
+
+// fileExtractor returns the extractor of a file of a static site, or nil when
+// the file is not parsed for links. URL lists are left out, since text files
+// such as robots.txt are published as they are
+func (o crawlOptions) fileExtractor(name string) Extractor {
+	extractor := cmp.Or(o.Extractors, DefaultExtractors).Lookup("", name)
+	if _, ok := extractor.(URLListExtractor); ok {
+		return nil
+	}
+	return extractor
+}
+
+// checks reports whether links of a kind should be checked
+func (o crawlOptions) checks(kind string) bool {
+	return len(o.Check) == 0 || slices.Contains(o.Check, kind)
+}
+
+// LinkResult stores the result of checking a link
+type LinkResult struct {
+	URL       string
+	Status    int
+	Error     error
+	ErrorKind ErrorKind // cause of Error, empty when the request succeeded
+	Severity  Severity  // ok, info, warning or error once checked, empty when Skipped
+	SourceURL string
+	Skipped   string        // reason the link was not fetched, empty if it was checked
+	Rule      string        // rule that excluded the link when Skipped is SkipExcluded
+	Attempts  int           // number of requests made, more than 1 when retried
+	Duration  time.Duration // time until the response, including retries and redirects
+	Redirects []Redirect
+	Warning   string     // problem that does not make the link broken, e.g. a permanent redirect
+	Info      string     // detail of an info result, e.g. a temporary redirect
+	Fragment  string     // #fragment missing from the target page, set on broken fragment results
+	Element   string     // HTML element the link was found in, e.g. "img"
+	Attribute string     // attribute of Element holding the link, e.g. "srcset"
+	Line      int        // 1-based line of the link in SourceURL, 0 if unknown
+	Column    int        // 1-based column of the link in SourceURL, counted in characters
+	Locations []Location // every place the link appears, the first is also in SourceURL
+}
+
+// fail records the error of a failed request, which makes the result broken
+func (r *LinkResult) fail(err error) {
+	r.Error = err
+	r.ErrorKind = errorKindOf(err)
+	r.Severity = SeverityError
+}
+
+// Broken reports whether the result is an error
+func (r LinkResult) Broken() bool {
+	return r.Severity == SeverityError
+}
+
+// AddLocation records a place the result's link appears
+// The first location also fills SourceURL, Element, Attribute, Line and Column
+func (r *LinkResult) AddLocation(loc Location) {
+	if loc.Source == "" {
+		return
+	}
+	if len(r.Locations) == 0 {
+		r.SourceURL = loc.Source
+		r.Element = loc.Element
+		r.Attribute = loc.Attribute
+		r.Line = loc.Line
+		r.Column = loc.Column
+	}
+	r.Locations = append(r.Locations, loc)
+}
+
+// Location is a place a link appears: the page or file it is in, its
+// position, and the element and attribute holding it in HTML
+type Location struct {
+	Source    string
+	Line      int
+	Column    int
+	Element   string
+	Attribute string
+}
+
+// String formats the location as "source:line:column (element[attribute])"
+func (l Location) String() string {
+	s := l.Source
+	if l.Line > 0 {
+		s += fmt.Sprintf(":%d:%d", l.Line, l.Column)
+	}
+	if l.Element != "" {
+		s += fmt.Sprintf(" (%s[%s])", l.Element, l.Attribute)
+	}
+	return s
+}
+
+// locationSet collects every location of each URL, safe for concurrent use
+type locationSet struct {
+	byURL map[string][]Location
+	mu    sync.Mutex
+}
+
+// newLocationSet creates an empty set
+func newLocationSet() *locationSet {
+	return &locationSet{byURL: make(map[string][]Location)}
+}
+
+// Add records a location of a URL
+func (s *locationSet) Add(url string, loc Location) {
+	s.mu.Lock()
+	s.byURL[url] = append(s.byURL[url], loc)
+	s.mu.Unlock()
+}
+
+// Attach sets the locations of each result from the set, sorted by position
+// Suppressed results keep the single location they were created with
+func (s *locationSet) Attach(results []LinkResult) {
+	for i := range results {
+		s.attach(&results[i])
+	}
+}
+
+// attach sets the locations of a single result
+func (s *locationSet) attach(result *LinkResult) {
+	if result.Skipped == SkipSuppressed {
+		return
+	}
+
+	s.mu.Lock()
+	locations := slices.Clone(s.byURL[result.URL])
+	s.mu.Unlock()
+
+	slices.SortFunc(locations, func(a, b Location) int {
+		return cmp.Or(
+			cmp.Compare(a.Source, b.Source),
+			cmp.Compare(a.Line, b.Line),
+			cmp.Compare(a.Column, b.Column),
+		)
+	})
+
+	result.Locations = nil
+	for _, loc := range locations {
+		result.AddLocation(loc)
+	}
+}
+
+// Link is a URL found in a page, with the element and attribute it came from
+type Link struct {
+	URL        string
+	Element    string
+	Attribute  string
+	Kind       string // one of LinkKinds
+	Line       int    // 1-based line of the link in its document
+	Column     int    // 1-based column of the link, counted in characters
+	Suppressed bool   // disabled by an inline directive, reported instead of checked
+}
+
+// At returns the location of the link in a page or file
+func (l Link) At(source string) Location {
+	return Location{
+		Source:    source,
+		Line:      l.Line,
+		Column:    l.Column,
+		Element:   l.Element,
+		Attribute: l.Attribute,
+	}
+}
+
+// SuppressedResult returns the result reporting a suppressed link at its location
+// Every suppressed occurrence is a result of its own, so each can be audited
+func (l Link) SuppressedResult(source string) LinkResult {
+	result := LinkResult{URL: l.URL, Skipped: SkipSuppressed}
+	result.AddLocation(l.At(source))
+	return result
+}
+
+// navigates reports whether a link leads to another page rather than a resource
+// of the current one, so the crawler follows it
+// Links without an element come from Markdown or URL lists, where every link
+// that is not an image leads to a page
+func (l Link) navigates() bool {
+	switch l.Element {
+	case "a", "area", "iframe":
+		return true
+	case "":
+		return l.Kind == KindLinks
+	}
+	return false
+}
+
+// Redirect is a single hop of a redirect chain
+type Redirect struct {
+	URL      string
+	Status   int
+	Location string
+	Duration time.Duration
+}
+
+// safeUrlMap provides thread-safe access to visited URLs
+type safeUrlMap struct {
+	visited map[string]bool
+	mu      sync.Mutex
+}
+
+// Visit marks a URL as visited, returns true if already visited
+func (s *safeUrlMap) Visit(url string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.visited[url] {
+		return true
+	}
+	s.visited[url] = true
+	return false
+}
+
+// crawlBudget enforces the page and external link limits of crawlOptions
+type crawlBudget struct {
+	opts      crawlOptions
+	pages     int
+	external  int
+	truncated bool
+	mu        sync.Mutex
+}
+
+// newCrawlBudget creates a budget for a single crawl
+func newCrawlBudget(opts crawlOptions) *crawlBudget {
+	return &crawlBudget{opts: opts}
+}
+
+// TakePage reserves a same-domain page fetch, returns false once the limit is reached
+func (b *crawlBudget) TakePage() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.opts.MaxPages > 0 && b.pages >= b.opts.MaxPages {
+		b.truncated = true
+		return false
+	}
+	b.pages++
+	return true
+}
+
+// TakeExternal reserves an external link check, returns false once the limit is reached
+func (b *crawlBudget) TakeExternal() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.opts.MaxExternal > 0 && b.external >= b.opts.MaxExternal {
+		b.truncated = true
+		return false
+	}
+	b.external++
+	return true
+}
+
+// Truncated reports whether any link was dropped because a limit was reached
+func (b *crawlBudget) Truncated() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.truncated
+}
@@ -0,0 +1,282 @@
+package linkcheck
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestSafeUrlMap_Visit(t *testing.T) {
+	tests := []struct {
+		name     string
+		url      string
+		wantNew  bool
+		wantSeen bool
+	}{
+		{
+			name:     "first visit returns false",
+			url:      "https://example.com",
+			wantNew:  false,
+			wantSeen: false,
+		},
+		{
+			name:     "second visit returns true",
+			url:      "https://example.com",
+			wantNew:  true,
+			wantSeen: true,
+		},
+		{
+			name:     "different URL returns false",
+			url:      "https://different.com",
+			wantNew:  false,
+			wantSeen: false,
+		},
+	}
+
+	visited := &safeUrlMap{visited: make(map[string]bool)}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := visited.Visit(tt.url)
+			if got != tt.wantSeen {
+				t.Errorf("Visit(%q) = %v, want %v", tt.url, got, tt.wantSeen)
+			}
+		})
+	}
+}
+
+func TestSafeUrlMap_ConcurrentAccess(t *testing.T) {
+	visited := &safeUrlMap{visited: make(map[string]bool)}
+	const goroutines = 100
+	const urlsPerGoroutine = 10
+
+	var wg sync.WaitGroup
+	wg.Add(goroutines)
+
+	// Spawn multiple goroutines trying to visit the same URLs
+	for i := range goroutines {
+		go func(id int) {
+			defer wg.Done()
+			for j := range urlsPerGoroutine {
+				url := "https://example.com/page-" + string(rune('0'+j))
+				visited.Visit(url)
+			}
+		}(i)
+	}
+
+	wg.Wait()
+
+	// Verify exactly urlsPerGoroutine URLs were stored
+	visited.mu.Lock()
+	count := len(visited.visited)
+	visited.mu.Unlock()
+
+	if count != urlsPerGoroutine {
+		t.Errorf("Expected %d unique URLs, got %d", urlsPerGoroutine, count)
+	}
+}
+
+func TestSafeUrlMap_RaceCondition(t *testing.T) {
+	// This test is designed to catch race conditions when run with -race flag
+	visited := &safeUrlMap{visited: make(map[string]bool)}
+	const workers = 50
+	url := "https://example.com"
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+
+	// Multiple goroutines visiting the same URL simultaneously
+	for range workers {
+		go func() {
+			defer wg.Done()
+			visited.Visit(url)
+		}()
+	}
+
+	wg.Wait()
+
+	// Exactly one goroutine should have seen it as "not visited"
+	// All others should have seen it as "already visited"
+	visited.mu.Lock()
+	if !visited.visited[url] {
+		t.Error("URL should be marked as visited")
+	}
+	visited.mu.Unlock()
+}
+
+func TestCrawlBudget(t *testing.T) {
+	tests := []struct {
+		name          string
+		opts          crawlOptions
+		pages         int
+		external      int
+		wantPages     int
+		wantExternal  int
+		wantTruncated bool
+	}{
+		{
+			name:         "unlimited",
+			opts:         crawlOptions{},
+			pages:        5,
+			external:     5,
+			wantPages:    5,
+			wantExternal: 5,
+		},
+		{
+			name:          "page limit",
+			opts:          crawlOptions{MaxPages: 2},
+			pages:         5,
+			wantPages:     2,
+			wantTruncated: true,
+		},
+		{
+			name:          "external limit",
+			opts:          crawlOptions{MaxExternal: 3},
+			external:      4,
+			wantExternal:  3,
+			wantTruncated: true,
+		},
+		{
+			name:         "within limits",
+			opts:         crawlOptions{MaxPages: 2, MaxExternal: 2},
+			pages:        2,
+			external:     2,
+			wantPages:    2,
+			wantExternal: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			budget := newCrawlBudget(tt.opts)
+
+			gotPages := 0
+			for range tt.pages {
+				if budget.TakePage() {
+					gotPages++
+				}
+			}
+			gotExternal := 0
+			for range tt.external {
+				if budget.TakeExternal() {
+					gotExternal++
+				}
+			}
+
+			if gotPages != tt.wantPages {
+				t.Errorf("TakePage() granted %d, want %d", gotPages, tt.wantPages)
+			}
+			if gotExternal != tt.wantExternal {
+				t.Errorf("TakeExternal() granted %d, want %d", gotExternal, tt.wantExternal)
+			}
+			if budget.Truncated() != tt.wantTruncated {
+				t.Errorf("Truncated() = %v, want %v", budget.Truncated(), tt.wantTruncated)
+			}
+		})
+	}
+}
+
+func TestCheckOptions_IsGetOnly(t *testing.T) {
+	opts := checkOptions{GetOnlyHosts: []string{"downloads.example.com", "Mirror.example.com:8080"}}
+
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{url: "https://downloads.example.com/release.tar.gz", want: true},
+		{url: "https://DOWNLOADS.example.com/", want: true},
+		{url: "https://downloads.example.com:8443/file", want: true},
+		{url: "https://mirror.example.com:8080/file", want: true},
+		{url: "https://mirror.example.com/file", want: false},
+		{url: "https://example.com/", want: false},
+		{url: "://invalid", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := opts.isGetOnly(tt.url); got != tt.want {
+			t.Errorf("isGetOnly(%q) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestLocationSet_Attach(t *testing.T) {
+	locations := newLocationSet()
+	locations.Add("https://example.com/a", Location{Source: "page2.html", Line: 3, Column: 1})
+	locations.Add("https://example.com/a", Location{Source: "page1.html", Line: 9, Column: 4, Element: "img", Attribute: "src"})
+	locations.Add("https://example.com/a", Location{Source: "page1.html", Line: 2, Column: 7})
+	locations.Add("https://example.com/b", Location{}) // command-line URLs have no source
+
+	results := []LinkResult{
+		{URL: "https://example.com/a"},
+		{URL: "https://example.com/b"},
+		{URL: "https://example.com/c"},
+	}
+	locations.Attach(results)
+
+	want := []Location{
+		{Source: "page1.html", Line: 2, Column: 7},
+		{Source: "page1.html", Line: 9, Column: 4, Element: "img", Attribute: "src"},
+		{Source: "page2.html", Line: 3, Column: 1},
+	}
+	if len(results[0].Locations) != len(want) {
+		t.Fatalf("Locations = %v, want %v", results[0].Locations, want)
+	}
+	for i := range want {
+		if results[0].Locations[i] != want[i] {
+			t.Errorf("Locations[%d] = %v, want %v", i, results[0].Locations[i], want[i])
+		}
+	}
+
+	// the first location also fills the single-source fields
+	if results[0].SourceURL != "page1.html" || results[0].Line != 2 || results[0].Column != 7 {
+		t.Errorf("Source = %s:%d:%d, want page1.html:2:7", results[0].SourceURL, results[0].Line, results[0].Column)
+	}
+
+	if len(results[1].Locations) != 0 || results[1].SourceURL != "" {
+		t.Errorf("Expected no locations without a source, got %v", results[1].Locations)
+	}
+	if len(results[2].Locations) != 0 {
+		t.Errorf("Expected no locations for an unreferenced URL, got %v", results[2].Locations)
+	}
+}
+
+func TestLocation_String(t *testing.T) {
+	tests := []struct {
+		loc  Location
+		want string
+	}{
+		{loc: Location{Source: "README.md"}, want: "README.md"},
+		{loc: Location{Source: "README.md", Line: 4, Column: 2}, want: "README.md:4:2"},
+		{loc: Location{Source: "https://example.com", Line: 10, Column: 5, Element: "a", Attribute: "href"}, want: "https://example.com:10:5 (a[href])"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.loc.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func BenchmarkSafeUrlMap_Visit(b *testing.B) {
+	visited := &safeUrlMap{visited: make(map[string]bool)}
+
+	b.ResetTimer()
+	i := 0
+	for b.Loop() {
+		url := "https://example.com/page-" + string(rune('0'+(i%10)))
+		visited.Visit(url)
+		i++
+	}
+}
+
+func BenchmarkSafeUrlMap_VisitParallel(b *testing.B) {
+	visited := &safeUrlMap{visited: make(map[string]bool)}
+
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			url := "https://example.com/page-" + string(rune('0'+(i%10)))
+			visited.Visit(url)
+			i++
+		}
+	})
+}
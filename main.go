@@ -2,211 +2,483 @@
 package main
 
 import (
-	"bufio"
-	"encoding/json"
+	"context"
 	"flag"
 	"fmt"
-	"net/http"
+	"net/url"
 	"os"
+	"os/signal"
+	"slices"
 	"strings"
-	"sync"
-	"time"
+	"syscall"
+
+	"gopkg.in/yaml.v3"
+
+	"linkchecker/linkcheck"
 )
 
 func main() {
 	// define flags
-	jsonFlag := flag.Bool("json", false, "Output results as JSON for CI/CD integration")
-	quietFlag := flag.Bool("quiet", false, "Suppress output, only show errors (useful with -json)")
-	timeoutFlag := flag.Duration("timeout", 10*time.Second, "HTTP request timeout (e.g., 10s, 30s, 1m)")
-	flag.Parse()
+	jsonFlag := flag.Bool("json", false, "Output results as JSON for CI/CD integration, same as -format json")
+	formatFlag := flag.String("format", "human", "Output format: human, json, or jsonl for one JSON object per line as each link is checked")
+	quietFlag := flag.Bool("quiet", false, "Suppress output, only show results that fail the run (useful with -json)")
+	failOnFlag := flag.String("fail-on", string(linkcheck.SeverityError), "Lowest severity that makes the run fail with exit status 1: warning or error")
+	slowFlag := flag.Duration("slow", 0, "Report links that take longer than this to respond as warnings (0 = never)")
+	timeoutFlag := flag.Duration("timeout", linkcheck.DefaultTimeout, "HTTP request timeout (e.g., 10s, 30s, 1m)")
+	maxTimeFlag := flag.Duration("max-time", 0, "Stop after this long and report the links checked so far (0 = no limit)")
+	depthFlag := flag.Int("depth", linkcheck.DefaultMaxDepth, "Maximum crawl depth from the start URL")
+	maxPagesFlag := flag.Int("max-pages", 0, "Maximum same-domain pages to fetch in crawl mode (0 = unlimited)")
+	maxExternalFlag := flag.Int("max-external", 0, "Maximum external links to check in crawl mode (0 = unlimited)")
+	concurrencyFlag := flag.Int("concurrency", linkcheck.DefaultConcurrency, "Maximum number of concurrent requests")
+	retriesFlag := flag.Int("retries", 0, "Retries after a network error, 429 or 5xx response")
+	retryBackoffFlag := flag.Duration("retry-backoff", linkcheck.DefaultRetryBackoff, "Delay before the first retry, doubled for each further retry")
+	maxRedirectsFlag := flag.Int("max-redirects", linkcheck.DefaultMaxRedirects, "Maximum redirects followed per link")
+	hostConcurrencyFlag := flag.Int("host-concurrency", 0, "Maximum concurrent requests per host (0 = unlimited)")
+	var getOnlyHosts stringList
+	flag.Var(&getOnlyHosts, "get-only", "Host that is checked with GET instead of HEAD (repeatable)")
+	var rateLimits linkcheck.RateLimits
+	userAgentFlag := flag.String("user-agent", linkcheck.DefaultUserAgent, "User-Agent header sent with requests and matched against robots.txt")
+	ignoreRobotsFlag := flag.Bool("ignore-robots", false, "Crawl pages even if robots.txt disallows them")
+	flag.Var(&rateLimits, "rate", "Requests per second, as req/s for all hosts or host=req/s for one host (repeatable)")
+	var checkKinds stringList
+	flag.Var(&checkKinds, "check", "Kinds of links to check in crawl and directory mode: "+strings.Join(linkcheck.LinkKinds, ",")+" (default all)")
+	baseURLFlag := flag.String("base-url", "", "URL a directory is published at, links under it are checked on disk")
+	includeCodeFlag := flag.Bool("include-code", false, "Also check URLs inside Markdown code spans and code blocks")
+	var headers headerList
+	flag.Var(&headers, "header", "Request header as \"Name: value\" (repeatable)")
+	var rules linkcheck.URLRules
+	flag.Var(&rules.Exclude, "exclude", "URL pattern not to check or follow: a glob, or a regular expression prefixed with re: (repeatable)")
+	flag.Var(&rules.Include, "include", "URL pattern to check even if excluded, or only these when nothing is excluded (repeatable)")
+	var excludeFiles, includeFiles stringList
+	flag.Var(&excludeFiles, "exclude-file", "File of -exclude patterns, one per line (repeatable)")
+	flag.Var(&includeFiles, "include-file", "File of -include patterns, one per line (repeatable)")
+	configFlag := flag.String("config", "", "Config file (default: "+configNames[0]+" in the working directory or a parent)")
+
+	// flags may also follow the arguments, e.g. "linkchecker ./public -base-url ..."
+	args := parseArgs(flag.CommandLine, os.Args[1:])
+
+	// -format given on the command line, unlike one from the config file,
+	// conflicts with -json
+	formatGiven := false
+	flag.Visit(func(f *flag.Flag) { formatGiven = formatGiven || f.Name == "format" })
+
+	// settings from the config file apply unless given on the command line
+	configFile := *configFlag
+	if configFile == "" {
+		if dir, err := os.Getwd(); err == nil {
+			configFile = findConfig(dir)
+		}
+	}
+	config := &Config{}
+	if configFile != "" {
+		var err error
+		if config, err = loadConfig(configFile); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: config %s: %v\n", configFile, err)
+			os.Exit(1)
+		}
+		if err := config.apply(flag.CommandLine); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: config %s: %v\n", configFile, err)
+			os.Exit(1)
+		}
+	}
+	if *jsonFlag {
+		if formatGiven && *formatFlag != "human" && *formatFlag != "json" {
+			fmt.Fprintf(os.Stderr, "Error: -json conflicts with -format %s\n", *formatFlag)
+			os.Exit(1)
+		}
+		*formatFlag = "json"
+	}
+	for _, file := range excludeFiles {
+		if err := linkcheck.ReadPatterns(file, &rules.Exclude); err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading -exclude-file: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	for _, file := range includeFiles {
+		if err := linkcheck.ReadPatterns(file, &rules.Include); err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading -include-file: %v\n", err)
+			os.Exit(1)
+		}
+	}
 
-	// get arguments
-	args := flag.Args()
+	if *depthFlag < 0 || *maxPagesFlag < 0 || *maxExternalFlag < 0 {
+		fmt.Fprintf(os.Stderr, "Error: -depth, -max-pages and -max-external must not be negative\n")
+		os.Exit(1)
+	}
+	if *concurrencyFlag < 1 {
+		fmt.Fprintf(os.Stderr, "Error: -concurrency must be at least 1\n")
+		os.Exit(1)
+	}
+	if *retriesFlag < 0 || *retryBackoffFlag < 0 {
+		fmt.Fprintf(os.Stderr, "Error: -retries and -retry-backoff must not be negative\n")
+		os.Exit(1)
+	}
+	if *maxRedirectsFlag < 1 {
+		fmt.Fprintf(os.Stderr, "Error: -max-redirects must be at least 1\n")
+		os.Exit(1)
+	}
+	if *hostConcurrencyFlag < 0 {
+		fmt.Fprintf(os.Stderr, "Error: -host-concurrency must not be negative\n")
+		os.Exit(1)
+	}
+	rateLimits.HostConcurrency = *hostConcurrencyFlag
+	for _, kind := range checkKinds {
+		if !slices.Contains(linkcheck.LinkKinds, kind) {
+			fmt.Fprintf(os.Stderr, "Error: unknown -check kind %q, expected %s\n", kind, strings.Join(linkcheck.LinkKinds, ","))
+			os.Exit(1)
+		}
+	}
+	if *formatFlag != "human" && *formatFlag != "json" && *formatFlag != "jsonl" {
+		fmt.Fprintf(os.Stderr, "Error: unknown -format %q, expected human, json or jsonl\n", *formatFlag)
+		os.Exit(1)
+	}
+	failOn, err := parseFailOn(*failOnFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if *slowFlag < 0 {
+		fmt.Fprintf(os.Stderr, "Error: -slow must not be negative\n")
+		os.Exit(1)
+	}
+	if *maxTimeFlag < 0 {
+		fmt.Fprintf(os.Stderr, "Error: -max-time must not be negative\n")
+		os.Exit(1)
+	}
+
+	// "linkchecker config validate" prints the settings in effect and exits
+	if len(args) > 1 && args[0] == "config" {
+		if len(args) != 2 || args[1] != "validate" {
+			fmt.Fprintf(os.Stderr, "Usage: %s config validate [options]\n", os.Args[0])
+			os.Exit(1)
+		}
+		if configFile == "" {
+			fmt.Fprintf(os.Stderr, "Error: no %s found in the working directory or its parents\n", configNames[0])
+			os.Exit(1)
+		}
+		if err := printConfig(configFile, config.merged(flag.CommandLine, headers.Header)); err != nil {
+			fmt.Fprintf(os.Stderr, "Error encoding config: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// check arguments
 	if len(args) == 0 {
-		fmt.Fprintf(os.Stderr, "Usage: %s [options] <url|file> [url|file...]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Usage: %s [options] <url|file|dir> [url|file...]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s config validate [options]\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "\nArguments:\n")
 		fmt.Fprintf(os.Stderr, "  url               Direct URL (http:// or https://)\n")
 		fmt.Fprintf(os.Stderr, "  file.md           Markdown file (extracts links)\n")
+		fmt.Fprintf(os.Stderr, "  file.html         HTML file (extracts links)\n")
 		fmt.Fprintf(os.Stderr, "  file.txt          URL list file (one URL per line)\n")
+		fmt.Fprintf(os.Stderr, "  dir               Built static site (checks HTML and Markdown files on disk)\n")
 		fmt.Fprintf(os.Stderr, "\nExamples:\n")
 		fmt.Fprintf(os.Stderr, "  %s https://example.com                    # Crawl mode (single URL)\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "  %s https://github.com https://google.com  # Direct check mode (multiple URLs)\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "  %s post.md                                # Check links in Markdown file\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "  %s docs/*.md                              # Check links in multiple Markdown files\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "  %s urls.txt                               # Check URLs from text file\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s ./public -base-url https://example.com # Check a built site without a server\n", os.Args[0])
 		os.Exit(1)
 	}
 
+	// files are read, and crawled pages parsed, by the extractor of their type
+	extractors := linkcheck.NewExtractors()
+	if *includeCodeFlag {
+		extractors.Register(linkcheck.MarkdownExtractor{IncludeCode: true}, "text/markdown", "text/x-markdown", ".md", ".markdown")
+	}
+
 	// process arguments and collect URLs
-	var urls []string
+	var origins []linkcheck.FileLink    // every URL occurrence, no source for command-line URLs
+	var localLinks []linkcheck.FileLink // relative links found in files, checked on disk
+	var suppressed []linkcheck.FileLink // links disabled by inline directives
+	siteRoot := ""
 	for _, arg := range args {
 		switch {
-		case strings.HasSuffix(arg, ".md"):
-			// Markdown file - extract links
-			content, err := os.ReadFile(arg)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error reading Markdown file %s: %v\n", arg, err)
+		case isDir(arg):
+			// static site directory - check files on disk
+			if len(args) > 1 {
+				fmt.Fprintf(os.Stderr, "Error: a directory must be the only argument\n")
 				os.Exit(1)
 			}
-			extractedURLs := extractMarkdownLinks(string(content))
-			if len(extractedURLs) == 0 {
-				fmt.Fprintf(os.Stderr, "Warning: No URLs found in %s\n", arg)
-			}
-			urls = append(urls, extractedURLs...)
+			siteRoot = arg
+
+		case strings.HasPrefix(arg, "http://") || strings.HasPrefix(arg, "https://"):
+			// Direct URL
+			origins = append(origins, linkcheck.FileLink{Link: linkcheck.Link{URL: arg}})
 
-		case strings.HasSuffix(arg, ".txt"):
-			// Text file - read URLs line by line
+		default:
+			// file - extract links with the extractor of its extension
+			extractor := extractors.Lookup("", arg)
+			if extractor == nil {
+				fmt.Fprintf(os.Stderr, "Error: Invalid argument '%s'\n", arg)
+				fmt.Fprintf(os.Stderr, "Expected: URL (http://...), a file with links (%s), or directory\n", strings.Join(extractors.Extensions(), ", "))
+				os.Exit(1)
+			}
 			file, err := os.Open(arg)
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "Error opening file %s: %v\n", arg, err)
 				os.Exit(1)
 			}
-			scanner := bufio.NewScanner(file)
-			for scanner.Scan() {
-				line := strings.TrimSpace(scanner.Text())
-				if line != "" && !strings.HasPrefix(line, "#") {
-					urls = append(urls, line)
-				}
-			}
+			links, err := extractor.Extract(file, nil)
 			file.Close()
-			if err := scanner.Err(); err != nil {
+			if err != nil {
 				fmt.Fprintf(os.Stderr, "Error reading file %s: %v\n", arg, err)
 				os.Exit(1)
 			}
+			// every entry of a URL list is checked, whatever its scheme
+			_, urlList := extractor.(linkcheck.URLListExtractor)
+			found := false
+			for _, link := range links {
+				fl := linkcheck.FileLink{Source: arg, Link: link}
+				switch {
+				case !urlList && !linkcheck.IsHTTPLink(link.URL) && !linkcheck.IsRelativeLink(link.URL):
+					continue // mailto:, ftp: and other schemes are not checked
+				case link.Suppressed:
+					suppressed = append(suppressed, fl)
+				case urlList || linkcheck.IsHTTPLink(link.URL):
+					origins = append(origins, fl)
+				default:
+					localLinks = append(localLinks, fl)
+				}
+				found = true
+			}
+			if !found {
+				fmt.Fprintf(os.Stderr, "Warning: No URLs found in %s\n", arg)
+			}
+		}
+	}
 
-		case strings.HasPrefix(arg, "http://") || strings.HasPrefix(arg, "https://"):
-			// Direct URL
-			urls = append(urls, arg)
-
-		default:
-			fmt.Fprintf(os.Stderr, "Error: Invalid argument '%s'\n", arg)
-			fmt.Fprintf(os.Stderr, "Expected: URL (http://...), Markdown file (.md), or URL list (.txt)\n")
-			os.Exit(1)
+	// each unique URL is checked once, with every place it was found
+	var urls []string
+	seen := make(map[string]struct{})
+	for _, origin := range origins {
+		if _, ok := seen[origin.Link.URL]; !ok {
+			seen[origin.Link.URL] = struct{}{}
+			urls = append(urls, origin.Link.URL)
 		}
 	}
 
 	// validate we have at least one URL
-	if len(urls) == 0 {
+	if siteRoot == "" && len(urls) == 0 && len(localLinks) == 0 && len(suppressed) == 0 {
 		fmt.Fprintf(os.Stderr, "Error: No URLs to check\n")
 		os.Exit(1)
 	}
 
-	// create HTTP client with configurable timeout
-	client := &http.Client{
-		Timeout: *timeoutFlag,
+	// links under the base URL are checked on disk, without a base URL every
+	// absolute http(s) link is external
+	var siteBase *url.URL
+	if *baseURLFlag != "" {
+		if siteRoot == "" {
+			fmt.Fprintf(os.Stderr, "Error: -base-url requires a directory argument\n")
+			os.Exit(1)
+		}
+		u, err := url.Parse(*baseURLFlag)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			fmt.Fprintf(os.Stderr, "Error: -base-url must be an absolute http(s) URL\n")
+			os.Exit(1)
+		}
+		siteBase = u
 	}
 
-	var results []LinkResult
+	options := []linkcheck.Option{
+		linkcheck.WithTimeout(*timeoutFlag),
+		linkcheck.WithRateLimits(rateLimits),
+		linkcheck.WithHeader(config.header(headers.Header)),
+		linkcheck.WithUserAgent(*userAgentFlag),
+		linkcheck.WithConcurrency(*concurrencyFlag),
+		linkcheck.WithRetries(*retriesFlag, *retryBackoffFlag),
+		linkcheck.WithMaxRedirects(*maxRedirectsFlag),
+		linkcheck.WithGetOnly(getOnlyHosts...),
+		linkcheck.WithRules(rules),
+		linkcheck.WithStatusRules(config.statusRules...),
+		linkcheck.WithSlowThreshold(*slowFlag),
+		linkcheck.WithMaxDepth(*depthFlag),
+		linkcheck.WithMaxPages(*maxPagesFlag),
+		linkcheck.WithMaxExternal(*maxExternalFlag),
+		linkcheck.WithIgnoreRobots(*ignoreRobotsFlag),
+		linkcheck.WithCheck(checkKinds...),
+		linkcheck.WithExtractors(extractors),
+	}
+	if *formatFlag == "jsonl" {
+		// each result is written as soon as it is checked
+		options = append(options, linkcheck.WithOnResult(streamJSONL(os.Stdout)))
+	}
+	checker, err := linkcheck.New(options...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Ctrl-C, SIGTERM or -max-time stop scheduling new checks; requests in
+	// flight finish and the links checked so far are reported
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		// a second signal is not caught and ends the process at once
+		signal.Stop(signals)
+		fmt.Fprintln(os.Stderr, "\nInterrupted: finishing requests in flight, press Ctrl-C again to quit")
+		cancel()
+	}()
+	if *maxTimeFlag > 0 {
+		var cancelTimeout context.CancelFunc
+		ctx, cancelTimeout = context.WithTimeout(ctx, *maxTimeFlag)
+		defer cancelTimeout()
+	}
+
+	var report *linkcheck.Report
+	// a banner line would break a JSON Lines stream
+	banner := !*quietFlag && *formatFlag != "jsonl"
 
 	// mode detection
-	if len(urls) == 1 {
+	if siteRoot != "" {
+		// directory - static site mode
+		if banner {
+			base := "file:///"
+			if siteBase != nil {
+				base = siteBase.String()
+			}
+			fmt.Printf("🔍 Checking site: %s (%s)\n\n", siteRoot, base)
+		}
+
+		report, err = checker.CheckSite(ctx, siteRoot, siteBase)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading directory %s: %v\n", siteRoot, err)
+			os.Exit(1)
+		}
+	} else if len(urls) == 1 && len(args) == 1 {
 		// single URL - crawl mode
 		startURL := urls[0]
-		if !*quietFlag {
-			fmt.Printf("🔍 Crawling: %s (depth: %d)\n\n", startURL, maxDepth)
+		if banner {
+			fmt.Printf("🔍 Crawling: %s (depth: %d)\n\n", startURL, *depthFlag)
 		}
 
-		visited := &SafeUrlMap{visited: make(map[string]bool)}
-		var resultsMu sync.Mutex
-		var wg sync.WaitGroup
+		report = checker.Crawl(ctx, startURL)
 
-		wg.Add(1)
-		go crawl(client, startURL, "", startURL, 0, visited, &results, &resultsMu, &wg)
-		wg.Wait()
+		// the start page was found in a file or given on the command line
+		for i := range report.Results {
+			if report.Results[i].URL == startURL {
+				for _, origin := range origins {
+					report.Results[i].AddLocation(origin.Link.At(origin.Source))
+				}
+			}
+		}
+		local := checker.CheckLinks(ctx, append(localLinks, suppressed...))
+		report.Results = append(report.Results, local.Results...)
+		report.Incomplete = local.Incomplete
 	} else {
 		// multiple URLs - direct check mode
-		if !*quietFlag {
-			fmt.Printf("🔍 Checking %d URLs...\n\n", len(urls))
+		if banner {
+			fmt.Printf("🔍 Checking %d URLs...\n\n", len(urls)+len(localLinks))
 		}
-		results = checkURLs(client, urls)
+		report = checker.CheckLinks(ctx, slices.Concat(origins, localLinks, suppressed))
 	}
+	results := report.Results
+	truncated, incomplete := report.Truncated, report.Incomplete
 
 	// display results
-	brokenCount := 0
+	brokenCount, failedCount := 0, 0
 	for _, result := range results {
-		if result.IsBroken {
+		if result.Broken() {
 			brokenCount++
 		}
+		if result.Severity.AtLeast(failOn) {
+			failedCount++
+		}
 	}
 
-	if *jsonFlag {
+	if *formatFlag == "jsonl" {
+		// the results have been streamed, only the summary is left
+		outputJSONLSummary(results, brokenCount, truncated, incomplete)
+	} else if *formatFlag == "json" {
 		// JSON output for CI/CD integration
-		outputJSON(results, brokenCount)
+		outputJSON(results, brokenCount, truncated, incomplete)
 	} else {
 		// Human-readable output
-		outputHuman(results, brokenCount, *quietFlag)
+		outputHuman(results, brokenCount, truncated, incomplete, *quietFlag, failOn)
 	}
 
-	if brokenCount > 0 {
+	// a partial report cannot vouch for the links it is missing
+	if failedCount > 0 || incomplete {
 		os.Exit(1)
 	}
 }
 
-// outputJSON outputs results in JSON format for CI/CD integration
-func outputJSON(results []LinkResult, brokenCount int) {
-	jsonResults := make([]JSONResult, len(results))
-	for i, result := range results {
-		var errStr *string
-		if result.Error != nil {
-			s := result.Error.Error()
-			errStr = &s
+// printConfig prints the file that was loaded and the merged settings as YAML
+func printConfig(file string, config *Config) error {
+	fmt.Printf("✓ Config: %s\n\n", file)
+	encoder := yaml.NewEncoder(os.Stdout)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(config); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// parseArgs parses flags given before, between or after the positional
+// arguments and returns the positional arguments
+func parseArgs(fs *flag.FlagSet, arguments []string) []string {
+	var positional []string
+	for {
+		// the flag package stops at the first non-flag argument
+		if err := fs.Parse(arguments); err != nil {
+			return positional
 		}
+		rest := fs.Args()
 
-		jsonResults[i] = JSONResult{
-			URL:       result.URL,
-			Status:    result.Status,
-			Error:     errStr,
-			Broken:    result.IsBroken,
-			SourceURL: result.SourceURL,
+		// everything after "--" is positional
+		if consumed := len(arguments) - len(rest); consumed > 0 && arguments[consumed-1] == "--" {
+			return append(positional, rest...)
 		}
-	}
 
-	output := JSONOutput{
-		Summary: JSONSummary{
-			Total:   len(results),
-			Broken:  brokenCount,
-			Success: len(results) - brokenCount,
-		},
-		Results: jsonResults,
+		arguments = rest
+		if len(arguments) == 0 {
+			return positional
+		}
+		positional = append(positional, arguments[0])
+		arguments = arguments[1:]
 	}
+}
 
-	encoder := json.NewEncoder(os.Stdout)
-	encoder.SetIndent("", "  ")
-	if err := encoder.Encode(output); err != nil {
-		fmt.Fprintf(os.Stderr, "Error encoding JSON: %v\n", err)
-		os.Exit(1)
-	}
+// isDir reports whether a path names an existing directory
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
 }
 
-// outputHuman outputs results in human-readable format
-func outputHuman(results []LinkResult, brokenCount int, quiet bool) {
-	if !quiet {
-		fmt.Println("Results:")
-		fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
-	}
+// stringList is a flag that can be repeated to collect several values
+type stringList []string
 
-	for _, result := range results {
-		if result.IsBroken {
-			if result.Error != nil {
-				fmt.Printf("✗ [error] %s\n", result.URL)
-				if result.SourceURL != "" {
-					fmt.Printf("  └─ Source: %s\n", result.SourceURL)
-				}
-				fmt.Printf("  └─ Error: %v\n", result.Error)
-			} else {
-				fmt.Printf("✗ [%d] %s\n", result.Status, result.URL)
-				if result.SourceURL != "" {
-					fmt.Printf("  └─ Source: %s\n", result.SourceURL)
-				}
-			}
-			fmt.Println()
-		} else if !quiet {
-			fmt.Printf("✓ [%d] %s\n", result.Status, result.URL)
+// String joins the collected values
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+// Get returns the collected values
+func (l *stringList) Get() any {
+	return []string(*l)
+}
+
+// Set appends a value, comma-separated values are split
+func (l *stringList) Set(value string) error {
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			*l = append(*l, v)
 		}
 	}
+	return nil
+}
 
-	if !quiet {
-		fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
-		fmt.Printf("Summary: %d checked, %d broken\n", len(results), brokenCount)
+// parseFailOn parses a -fail-on threshold, only warning and error make sense
+// as an exit status
+func parseFailOn(value string) (linkcheck.Severity, error) {
+	switch s := linkcheck.Severity(value); s {
+	case linkcheck.SeverityWarning, linkcheck.SeverityError:
+		return s, nil
 	}
+	return "", fmt.Errorf("unknown -fail-on %q, expected warning or error", value)
 }
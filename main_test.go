@@ -2,320 +2,103 @@ package main
 
 import (
 	"bufio"
-	"bytes"
-	"encoding/json"
-	"errors"
-	"io"
-	"net/http"
+	"flag"
 	"os"
 	"strings"
 	"testing"
+
+	"linkchecker/linkcheck"
 )
 
-func TestOutputJSON(t *testing.T) {
+func TestParseArgs(t *testing.T) {
 	tests := []struct {
-		name        string
-		results     []LinkResult
-		brokenCount int
-		wantTotal   int
-		wantBroken  int
-		wantSuccess int
+		name     string
+		args     []string
+		wantArgs []string
+		wantBase string
+		wantJSON bool
 	}{
 		{
-			name: "all successful",
-			results: []LinkResult{
-				{URL: "https://example.com", Status: 200, IsBroken: false},
-				{URL: "https://example.com/page", Status: 200, IsBroken: false},
-			},
-			brokenCount: 0,
-			wantTotal:   2,
-			wantBroken:  0,
-			wantSuccess: 2,
+			name:     "flags before arguments",
+			args:     []string{"-json", "-base-url", "https://example.com", "./public"},
+			wantArgs: []string{"./public"},
+			wantBase: "https://example.com",
+			wantJSON: true,
+		},
+		{
+			name:     "flags after arguments",
+			args:     []string{"./public", "--base-url", "https://example.com", "-json"},
+			wantArgs: []string{"./public"},
+			wantBase: "https://example.com",
+			wantJSON: true,
 		},
 		{
-			name: "mixed results",
-			results: []LinkResult{
-				{URL: "https://example.com", Status: 200, IsBroken: false},
-				{URL: "https://example.com/404", Status: 404, IsBroken: true, SourceURL: "https://example.com"},
-				{URL: "https://broken.com", Status: 0, Error: errors.New("connection refused"), IsBroken: true},
-			},
-			brokenCount: 2,
-			wantTotal:   3,
-			wantBroken:  2,
-			wantSuccess: 1,
+			name:     "flags between arguments",
+			args:     []string{"a.md", "-json", "b.md"},
+			wantArgs: []string{"a.md", "b.md"},
+			wantJSON: true,
 		},
 		{
-			name:        "empty results",
-			results:     []LinkResult{},
-			brokenCount: 0,
-			wantTotal:   0,
-			wantBroken:  0,
-			wantSuccess: 0,
+			name:     "double dash ends flags",
+			args:     []string{"a.md", "--", "-json"},
+			wantArgs: []string{"a.md", "-json"},
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// Capture stdout
-			oldStdout := os.Stdout
-			r, w, _ := os.Pipe()
-			os.Stdout = w
-
-			outputJSON(tt.results, tt.brokenCount)
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			jsonFlag := fs.Bool("json", false, "")
+			baseURLFlag := fs.String("base-url", "", "")
 
-			w.Close()
-			os.Stdout = oldStdout
-
-			var buf bytes.Buffer
-			io.Copy(&buf, r)
-
-			// Parse JSON output
-			var output JSONOutput
-			if err := json.Unmarshal(buf.Bytes(), &output); err != nil {
-				t.Fatalf("Failed to parse JSON output: %v", err)
+			got := parseArgs(fs, tt.args)
+			if strings.Join(got, " ") != strings.Join(tt.wantArgs, " ") {
+				t.Errorf("parseArgs() = %q, want %q", got, tt.wantArgs)
 			}
-
-			// Verify summary
-			if output.Summary.Total != tt.wantTotal {
-				t.Errorf("Total = %d, want %d", output.Summary.Total, tt.wantTotal)
-			}
-			if output.Summary.Broken != tt.wantBroken {
-				t.Errorf("Broken = %d, want %d", output.Summary.Broken, tt.wantBroken)
-			}
-			if output.Summary.Success != tt.wantSuccess {
-				t.Errorf("Success = %d, want %d", output.Summary.Success, tt.wantSuccess)
-			}
-
-			// Verify results count
-			if len(output.Results) != len(tt.results) {
-				t.Errorf("Results count = %d, want %d", len(output.Results), len(tt.results))
+			if *baseURLFlag != tt.wantBase || *jsonFlag != tt.wantJSON {
+				t.Errorf("flags = %q, %v, want %q, %v", *baseURLFlag, *jsonFlag, tt.wantBase, tt.wantJSON)
 			}
 		})
 	}
 }
 
-func TestOutputJSON_ErrorHandling(t *testing.T) {
-	results := []LinkResult{
-		{
-			URL:       "https://example.com/error",
-			Status:    0,
-			Error:     errors.New("network timeout"),
-			IsBroken:  true,
-			SourceURL: "https://example.com",
-		},
-	}
-
-	// Capture stdout
-	oldStdout := os.Stdout
-	r, w, _ := os.Pipe()
-	os.Stdout = w
-
-	outputJSON(results, 1)
-
-	w.Close()
-	os.Stdout = oldStdout
-
-	var buf bytes.Buffer
-	io.Copy(&buf, r)
-
-	var output JSONOutput
-	if err := json.Unmarshal(buf.Bytes(), &output); err != nil {
-		t.Fatalf("Failed to parse JSON: %v", err)
-	}
-
-	// Verify error is included in JSON
-	if output.Results[0].Error == nil {
-		t.Error("Expected error field to be present")
-	}
-
-	if *output.Results[0].Error != "network timeout" {
-		t.Errorf("Error = %s, want 'network timeout'", *output.Results[0].Error)
-	}
-
-	if !output.Results[0].Broken {
-		t.Error("Expected broken to be true")
-	}
-}
-
-func TestOutputHuman_NormalMode(t *testing.T) {
-	results := []LinkResult{
-		{URL: "https://example.com", Status: 200, IsBroken: false},
-		{URL: "https://example.com/404", Status: 404, IsBroken: true, SourceURL: "https://example.com"},
-	}
-
-	// Capture stdout
-	oldStdout := os.Stdout
-	r, w, _ := os.Pipe()
-	os.Stdout = w
-
-	outputHuman(results, 1, false)
-
-	w.Close()
-	os.Stdout = oldStdout
-
-	var buf bytes.Buffer
-	io.Copy(&buf, r)
-	output := buf.String()
-
-	// Verify output contains key elements
-	if !strings.Contains(output, "Results:") {
-		t.Error("Expected 'Results:' header")
-	}
-
-	if !strings.Contains(output, "✓ [200] https://example.com") {
-		t.Error("Expected successful link output")
-	}
-
-	if !strings.Contains(output, "✗ [404] https://example.com/404") {
-		t.Error("Expected broken link output")
-	}
-
-	if !strings.Contains(output, "Summary:") {
-		t.Error("Expected summary")
-	}
-}
-
-func TestOutputHuman_QuietMode(t *testing.T) {
-	results := []LinkResult{
-		{URL: "https://example.com", Status: 200, IsBroken: false},
-		{URL: "https://example.com/404", Status: 404, IsBroken: true, SourceURL: "https://example.com"},
-		{URL: "https://error.com", Status: 0, Error: errors.New("timeout"), IsBroken: true},
-	}
-
-	// Capture stdout
-	oldStdout := os.Stdout
-	r, w, _ := os.Pipe()
-	os.Stdout = w
-
-	outputHuman(results, 2, true)
-
-	w.Close()
-	os.Stdout = oldStdout
-
-	var buf bytes.Buffer
-	io.Copy(&buf, r)
-	output := buf.String()
-
-	// In quiet mode, should NOT contain success messages
-	if strings.Contains(output, "✓ [200]") {
-		t.Error("Quiet mode should not show successful links")
-	}
-
-	if strings.Contains(output, "Results:") {
-		t.Error("Quiet mode should not show 'Results:' header")
-	}
-
-	if strings.Contains(output, "Summary:") {
-		t.Error("Quiet mode should not show summary")
-	}
-
-	// Should still show broken links
-	if !strings.Contains(output, "✗ [404]") {
-		t.Error("Quiet mode should show broken links")
-	}
-
-	if !strings.Contains(output, "✗ [error]") {
-		t.Error("Quiet mode should show error links")
-	}
-}
-
-func TestOutputHuman_WithError(t *testing.T) {
-	results := []LinkResult{
-		{
-			URL:       "https://example.com/timeout",
-			Status:    0,
-			Error:     errors.New("connection timeout"),
-			IsBroken:  true,
-			SourceURL: "https://example.com",
-		},
-	}
-
-	// Capture stdout
-	oldStdout := os.Stdout
-	r, w, _ := os.Pipe()
-	os.Stdout = w
-
-	outputHuman(results, 1, false)
-
-	w.Close()
-	os.Stdout = oldStdout
-
-	var buf bytes.Buffer
-	io.Copy(&buf, r)
-	output := buf.String()
-
-	// Verify error output format
-	if !strings.Contains(output, "✗ [error]") {
-		t.Error("Expected error marker")
-	}
-
-	if !strings.Contains(output, "connection timeout") {
-		t.Error("Expected error message")
-	}
-
-	if !strings.Contains(output, "Source: https://example.com") {
-		t.Error("Expected source URL")
-	}
-}
-
-func TestJSONResult_Serialization(t *testing.T) {
-	errMsg := "test error"
-	result := JSONResult{
-		URL:       "https://example.com",
-		Status:    http.StatusNotFound,
-		Error:     &errMsg,
-		Broken:    true,
-		SourceURL: "https://source.com",
-	}
-
-	data, err := json.Marshal(result)
-	if err != nil {
-		t.Fatalf("Failed to marshal JSON: %v", err)
-	}
+func TestStringList(t *testing.T) {
+	var list stringList
+	list.Set("a.example.com")
+	list.Set("b.example.com, c.example.com")
+	list.Set("")
 
-	var decoded JSONResult
-	if err := json.Unmarshal(data, &decoded); err != nil {
-		t.Fatalf("Failed to unmarshal JSON: %v", err)
+	want := []string{"a.example.com", "b.example.com", "c.example.com"}
+	if len(list) != len(want) {
+		t.Fatalf("stringList = %v, want %v", list, want)
 	}
-
-	if decoded.URL != result.URL {
-		t.Errorf("URL = %s, want %s", decoded.URL, result.URL)
-	}
-
-	if decoded.Status != result.Status {
-		t.Errorf("Status = %d, want %d", decoded.Status, result.Status)
-	}
-
-	if decoded.Error == nil || *decoded.Error != *result.Error {
-		t.Errorf("Error mismatch")
+	for i := range want {
+		if list[i] != want[i] {
+			t.Errorf("stringList[%d] = %q, want %q", i, list[i], want[i])
+		}
 	}
 
-	if decoded.Broken != result.Broken {
-		t.Errorf("Broken = %v, want %v", decoded.Broken, result.Broken)
+	if got := list.String(); got != "a.example.com,b.example.com,c.example.com" {
+		t.Errorf("String() = %q", got)
 	}
 }
 
-func TestJSONResult_NilError(t *testing.T) {
-	result := JSONResult{
-		URL:    "https://example.com",
-		Status: http.StatusOK,
-		Error:  nil,
-		Broken: false,
-	}
-
-	data, err := json.Marshal(result)
-	if err != nil {
-		t.Fatalf("Failed to marshal JSON: %v", err)
-	}
+// Integration tests for file processing
 
-	// Verify error field is omitted when nil
-	if strings.Contains(string(data), "error") {
-		t.Error("Expected error field to be omitted when nil")
+// markdownURLs returns the unique http(s) URLs of Markdown content, as the
+// Markdown extractor finds them
+func markdownURLs(content string) []string {
+	var urls []string
+	seen := make(map[string]bool)
+	for _, link := range linkcheck.MarkdownLinks(content, linkcheck.MarkdownOptions{}) {
+		if linkcheck.IsHTTPLink(link.URL) && !link.Suppressed && !seen[link.URL] {
+			urls = append(urls, link.URL)
+			seen[link.URL] = true
+		}
 	}
+	return urls
 }
 
-// Integration tests for file processing
-
 func TestMarkdownFileProcessing(t *testing.T) {
 	// Create a temporary Markdown file
 	content := `# Test Document
@@ -343,7 +126,7 @@ Also see:
 		t.Fatalf("Failed to read temp file: %v", err)
 	}
 
-	urls := extractMarkdownLinks(string(fileContent))
+	urls := markdownURLs(string(fileContent))
 
 	// Verify extracted URLs
 	expectedURLs := []string{
@@ -444,7 +227,7 @@ Just some text with no URLs.
 		t.Fatalf("Failed to read temp file: %v", err)
 	}
 
-	urls := extractMarkdownLinks(string(fileContent))
+	urls := markdownURLs(string(fileContent))
 
 	if len(urls) != 0 {
 		t.Errorf("Expected 0 URLs, got %d: %v", len(urls), urls)
@@ -533,14 +316,13 @@ Also check https://github.com/example/api for more.
 		t.Fatalf("Failed to read temp file: %v", err)
 	}
 
-	urls := extractMarkdownLinks(string(fileContent))
+	urls := markdownURLs(string(fileContent))
 
-	// Verify we extracted the important URLs
+	// Verify we extracted the important URLs, the one in the code block is skipped
 	expectedURLs := map[string]bool{
 		"https://api.example.com/v1":   true,
 		"https://docs.example.com/users": true,
 		"https://oauth.example.com":    true,
-		"https://api.example.com/login": true,
 		"https://github.com/example/api": true,
 	}
 
@@ -555,3 +337,16 @@ Also check https://github.com/example/api for more.
 		t.Errorf("Missing URLs: %v", expectedURLs)
 	}
 }
+
+func TestParseFailOn(t *testing.T) {
+	for _, value := range []string{"warning", "error"} {
+		if got, err := parseFailOn(value); err != nil || got != linkcheck.Severity(value) {
+			t.Errorf("parseFailOn(%q) = %q, %v", value, got, err)
+		}
+	}
+	for _, value := range []string{"info", "ok", "broken", ""} {
+		if _, err := parseFailOn(value); err == nil {
+			t.Errorf("parseFailOn(%q) error = nil, want an error", value)
+		}
+	}
+}
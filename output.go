@@ -0,0 +1,374 @@
+// output.go - Human-readable, JSON and JSON Lines reports
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"linkchecker/linkcheck"
+)
+
+// JSONOutput represents the machine-readable output format for CI/CD integration
+type JSONOutput struct {
+	Summary JSONSummary  `json:"summary"`
+	Results []JSONResult `json:"results"`
+}
+
+// JSONLSummary is the last record of -format jsonl, after one JSONResult per line
+type JSONLSummary struct {
+	Summary JSONSummary `json:"summary"`
+}
+
+// JSONSummary contains aggregate statistics
+// Broken and Success are kept for existing consumers: Broken equals Errors
+// and Success counts the ok and info results
+type JSONSummary struct {
+	Total      int  `json:"total"`
+	Broken     int  `json:"broken"`
+	Success    int  `json:"success"`
+	OK         int  `json:"ok"`
+	Info       int  `json:"info"`
+	Warnings   int  `json:"warnings"`
+	Errors     int  `json:"errors"`
+	Skipped    int  `json:"skipped"`
+	Excluded   int  `json:"excluded"`
+	Suppressed int  `json:"suppressed"`
+	Truncated  bool `json:"truncated"`
+	Incomplete bool `json:"incomplete"` // stopped by a signal or -max-time before every link was checked
+
+	ErrorKinds map[linkcheck.ErrorKind]int `json:"error_kinds,omitempty"` // failed requests per cause
+}
+
+// JSONResult represents a single link check result
+type JSONResult struct {
+	URL        string         `json:"url"`
+	Status     int            `json:"status"`
+	Error      *string        `json:"error,omitempty"`
+	ErrorKind  string         `json:"error_kind,omitempty"`
+	Broken     bool           `json:"broken"`
+	Severity   string         `json:"severity,omitempty"`
+	SourceURL  string         `json:"source,omitempty"`
+	Skipped    string         `json:"skipped,omitempty"`
+	Rule       string         `json:"rule,omitempty"`
+	Attempts   int            `json:"attempts,omitempty"`
+	DurationMs int64          `json:"duration_ms,omitempty"`
+	Redirects  []JSONRedirect `json:"redirects,omitempty"`
+	Warning    string         `json:"warning,omitempty"`
+	Info       string         `json:"info,omitempty"`
+	Fragment   string         `json:"fragment,omitempty"`
+	Element    string         `json:"element,omitempty"`
+	Attribute  string         `json:"attribute,omitempty"`
+	Line       int            `json:"line,omitempty"`
+	Column     int            `json:"column,omitempty"`
+	Locations  []JSONLocation `json:"locations,omitempty"`
+}
+
+// JSONLocation is a single place a link appears
+type JSONLocation struct {
+	Source    string `json:"source"`
+	Line      int    `json:"line,omitempty"`
+	Column    int    `json:"column,omitempty"`
+	Element   string `json:"element,omitempty"`
+	Attribute string `json:"attribute,omitempty"`
+}
+
+// JSONRedirect represents a single redirect hop
+type JSONRedirect struct {
+	URL        string `json:"url"`
+	Status     int    `json:"status"`
+	Location   string `json:"location"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// outputJSON outputs results in JSON format for CI/CD integration
+func outputJSON(results []linkcheck.LinkResult, brokenCount int, truncated, incomplete bool) {
+	jsonResults := make([]JSONResult, len(results))
+	for i, result := range results {
+		jsonResults[i] = newJSONResult(result)
+	}
+
+	output := JSONOutput{
+		Summary: newJSONSummary(results, brokenCount, truncated, incomplete),
+		Results: jsonResults,
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(output); err != nil {
+		fmt.Fprintf(os.Stderr, "Error encoding JSON: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// streamJSONL returns a callback that writes each result to w as a line of JSON
+func streamJSONL(w io.Writer) func(linkcheck.LinkResult) {
+	encoder := json.NewEncoder(w)
+	return func(result linkcheck.LinkResult) {
+		if err := encoder.Encode(newJSONResult(result)); err != nil {
+			fmt.Fprintf(os.Stderr, "Error encoding JSON: %v\n", err)
+			os.Exit(1)
+		}
+	}
+}
+
+// outputJSONLSummary ends a JSON Lines stream with a {"summary": ...} record
+func outputJSONLSummary(results []linkcheck.LinkResult, brokenCount int, truncated, incomplete bool) {
+	output := JSONLSummary{Summary: newJSONSummary(results, brokenCount, truncated, incomplete)}
+	if err := json.NewEncoder(os.Stdout).Encode(output); err != nil {
+		fmt.Fprintf(os.Stderr, "Error encoding JSON: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// newJSONResult converts a result to its JSON form
+func newJSONResult(result linkcheck.LinkResult) JSONResult {
+	var errStr *string
+	if result.Error != nil {
+		s := result.Error.Error()
+		errStr = &s
+	}
+
+	jsonResult := JSONResult{
+		URL:        result.URL,
+		Status:     result.Status,
+		Error:      errStr,
+		ErrorKind:  string(result.ErrorKind),
+		Broken:     result.Broken(),
+		Severity:   string(result.Severity),
+		SourceURL:  result.SourceURL,
+		Skipped:    result.Skipped,
+		Rule:       result.Rule,
+		Attempts:   result.Attempts,
+		DurationMs: result.Duration.Milliseconds(),
+		Warning:    result.Warning,
+		Info:       result.Info,
+		Fragment:   result.Fragment,
+		Element:    result.Element,
+		Attribute:  result.Attribute,
+		Line:       result.Line,
+		Column:     result.Column,
+	}
+	for _, loc := range result.Locations {
+		jsonResult.Locations = append(jsonResult.Locations, JSONLocation{
+			Source:    loc.Source,
+			Line:      loc.Line,
+			Column:    loc.Column,
+			Element:   loc.Element,
+			Attribute: loc.Attribute,
+		})
+	}
+	for _, redirect := range result.Redirects {
+		jsonResult.Redirects = append(jsonResult.Redirects, JSONRedirect{
+			URL:        redirect.URL,
+			Status:     redirect.Status,
+			Location:   redirect.Location,
+			DurationMs: redirect.Duration.Milliseconds(),
+		})
+	}
+	return jsonResult
+}
+
+// newJSONSummary counts results per severity, skip reason and failure cause
+func newJSONSummary(results []linkcheck.LinkResult, brokenCount int, truncated, incomplete bool) JSONSummary {
+	skippedCount, excludedCount, suppressedCount := 0, 0, 0
+	levels := make(map[linkcheck.Severity]int)
+	var errorKinds map[linkcheck.ErrorKind]int
+	for _, result := range results {
+		switch {
+		case result.Skipped == linkcheck.SkipExcluded:
+			excludedCount++
+		case result.Skipped == linkcheck.SkipSuppressed:
+			suppressedCount++
+		case result.Skipped != "":
+			skippedCount++
+		default:
+			levels[result.Severity]++
+		}
+		if result.ErrorKind != "" {
+			if errorKinds == nil {
+				errorKinds = make(map[linkcheck.ErrorKind]int)
+			}
+			errorKinds[result.ErrorKind]++
+		}
+	}
+
+	return JSONSummary{
+		Total:      len(results),
+		Broken:     brokenCount,
+		Success:    levels[linkcheck.SeverityOK] + levels[linkcheck.SeverityInfo],
+		OK:         levels[linkcheck.SeverityOK],
+		Info:       levels[linkcheck.SeverityInfo],
+		Warnings:   levels[linkcheck.SeverityWarning],
+		Errors:     levels[linkcheck.SeverityError],
+		Skipped:    skippedCount,
+		Excluded:   excludedCount,
+		Suppressed: suppressedCount,
+		Truncated:  truncated,
+		Incomplete: incomplete,
+		ErrorKinds: errorKinds,
+	}
+}
+
+// outputHuman outputs results in human-readable format, each severity with its
+// own marker. In quiet mode only the results at or above failOn are shown
+func outputHuman(results []linkcheck.LinkResult, brokenCount int, truncated, incomplete, quiet bool, failOn linkcheck.Severity) {
+	if !quiet {
+		fmt.Println("Results:")
+		fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	}
+
+	skippedCount, excludedCount, suppressedCount, warningCount, infoCount := 0, 0, 0, 0, 0
+	errorKinds := make(map[linkcheck.ErrorKind]int)
+	for _, result := range results {
+		if result.ErrorKind != "" {
+			errorKinds[result.ErrorKind]++
+		}
+		if result.Skipped == linkcheck.SkipSuppressed {
+			suppressedCount++
+			if !quiet {
+				fmt.Printf("⊘ [suppressed] %s\n", result.URL)
+				printSource(result)
+			}
+			continue
+		}
+		if result.Skipped == linkcheck.SkipExcluded {
+			excludedCount++
+			if !quiet {
+				fmt.Printf("⊘ [excluded] %s\n", result.URL)
+				fmt.Printf("  └─ Rule: %s\n", result.Rule)
+			}
+			continue
+		}
+		if result.Skipped != "" {
+			skippedCount++
+			if !quiet {
+				fmt.Printf("⊘ [skipped (%s)] %s\n", result.Skipped, result.URL)
+			}
+			continue
+		}
+
+		if result.Broken() {
+			if result.Fragment != "" {
+				page, _, _ := strings.Cut(result.URL, "#")
+				fmt.Printf("✗ [broken fragment] %s\n", result.URL)
+				printSource(result)
+				fmt.Printf("  └─ Fragment: #%s not found on %s\n", result.Fragment, page)
+			} else if result.Error != nil {
+				fmt.Printf("✗ [%s] %s\n", cmp.Or(string(result.ErrorKind), "error"), result.URL)
+				printSource(result)
+				fmt.Printf("  └─ Error: %v\n", result.Error)
+			} else {
+				fmt.Printf("✗ [%d] %s\n", result.Status, result.URL)
+				printSource(result)
+			}
+			printRedirects(result)
+			if result.Attempts > 1 {
+				fmt.Printf("  └─ Attempts: %d\n", result.Attempts)
+			}
+			fmt.Println()
+		} else if result.Severity == linkcheck.SeverityWarning {
+			warningCount++
+			if quiet && !result.Severity.AtLeast(failOn) {
+				continue
+			}
+			fmt.Printf("⚠ [%d] %s\n", result.Status, result.URL)
+			printSource(result)
+			printRedirects(result)
+			fmt.Printf("  └─ Warning: %s\n", result.Warning)
+		} else if result.Severity == linkcheck.SeverityInfo {
+			infoCount++
+			if quiet {
+				continue
+			}
+			fmt.Printf("ℹ [%d] %s\n", result.Status, result.URL)
+			printRedirects(result)
+			fmt.Printf("  └─ Info: %s\n", result.Info)
+		} else if !quiet {
+			if result.Attempts > 1 {
+				fmt.Printf("✓ [%d] %s (after %d attempts)\n", result.Status, result.URL, result.Attempts)
+			} else {
+				fmt.Printf("✓ [%d] %s\n", result.Status, result.URL)
+			}
+			printRedirects(result)
+		}
+	}
+
+	if !quiet {
+		fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+		summary := fmt.Sprintf("Summary: %d checked, %d broken", len(results)-skippedCount-excludedCount-suppressedCount, brokenCount)
+		if warningCount > 0 {
+			summary += fmt.Sprintf(", %d warnings", warningCount)
+		}
+		if infoCount > 0 {
+			summary += fmt.Sprintf(", %d info", infoCount)
+		}
+		if skippedCount > 0 {
+			summary += fmt.Sprintf(", %d skipped", skippedCount)
+		}
+		if excludedCount > 0 {
+			summary += fmt.Sprintf(", %d excluded", excludedCount)
+		}
+		if suppressedCount > 0 {
+			summary += fmt.Sprintf(", %d suppressed", suppressedCount)
+		}
+		fmt.Println(summary)
+
+		if len(errorKinds) > 0 {
+			var causes []string
+			for _, kind := range slices.Sorted(maps.Keys(errorKinds)) {
+				causes = append(causes, fmt.Sprintf("%d %s", errorKinds[kind], kind))
+			}
+			fmt.Printf("Failed requests: %s\n", strings.Join(causes, ", "))
+		}
+	}
+
+	// always warn about truncation, a partial crawl can hide broken links
+	if truncated {
+		fmt.Println("⚠ Crawl truncated: -max-pages or -max-external limit reached")
+	}
+	if incomplete {
+		fmt.Println("⚠ Incomplete: interrupted or -max-time reached, links not checked yet are missing")
+	}
+}
+
+// printSource prints where a result was found as "source:line:column",
+// followed by the element referencing it, or every location when there are several
+func printSource(result linkcheck.LinkResult) {
+	if len(result.Locations) > 1 {
+		fmt.Printf("  └─ Sources (%d):\n", len(result.Locations))
+		for _, loc := range result.Locations {
+			fmt.Printf("       %s\n", loc)
+		}
+		return
+	}
+
+	if result.SourceURL == "" {
+		return
+	}
+	fmt.Printf("  └─ Source: %s\n", linkcheck.Location{
+		Source:    result.SourceURL,
+		Line:      result.Line,
+		Column:    result.Column,
+		Element:   result.Element,
+		Attribute: result.Attribute,
+	})
+}
+
+// printRedirects prints the redirect chain of a result as an indented trail
+func printRedirects(result linkcheck.LinkResult) {
+	if len(result.Redirects) == 0 {
+		return
+	}
+
+	fmt.Println("  └─ Redirects:")
+	for _, redirect := range result.Redirects {
+		fmt.Printf("       %d → %s (%s)\n", redirect.Status, redirect.Location, redirect.Duration.Round(time.Millisecond))
+	}
+}
@@ -0,0 +1,1119 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"linkchecker/linkcheck"
+)
+
+func TestOutputJSON(t *testing.T) {
+	tests := []struct {
+		name        string
+		results     []linkcheck.LinkResult
+		brokenCount int
+		wantTotal   int
+		wantBroken  int
+		wantSuccess int
+	}{
+		{
+			name: "all successful",
+			results: []linkcheck.LinkResult{
+				{URL: "https://example.com", Status: 200, Severity: linkcheck.SeverityOK},
+				{URL: "https://example.com/page", Status: 200, Severity: linkcheck.SeverityOK},
+			},
+			brokenCount: 0,
+			wantTotal:   2,
+			wantBroken:  0,
+			wantSuccess: 2,
+		},
+		{
+			name: "mixed results",
+			results: []linkcheck.LinkResult{
+				{URL: "https://example.com", Status: 200, Severity: linkcheck.SeverityOK},
+				{URL: "https://example.com/404", Status: 404, Severity: linkcheck.SeverityError, SourceURL: "https://example.com"},
+				{URL: "https://broken.com", Status: 0, Error: errors.New("connection refused"), Severity: linkcheck.SeverityError},
+			},
+			brokenCount: 2,
+			wantTotal:   3,
+			wantBroken:  2,
+			wantSuccess: 1,
+		},
+		{
+			name:        "empty results",
+			results:     []linkcheck.LinkResult{},
+			brokenCount: 0,
+			wantTotal:   0,
+			wantBroken:  0,
+			wantSuccess: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Capture stdout
+			oldStdout := os.Stdout
+			r, w, _ := os.Pipe()
+			os.Stdout = w
+
+			outputJSON(tt.results, tt.brokenCount, false, false)
+
+			w.Close()
+			os.Stdout = oldStdout
+
+			var buf bytes.Buffer
+			io.Copy(&buf, r)
+
+			// Parse JSON output
+			var output JSONOutput
+			if err := json.Unmarshal(buf.Bytes(), &output); err != nil {
+				t.Fatalf("Failed to parse JSON output: %v", err)
+			}
+
+			// Verify summary
+			if output.Summary.Total != tt.wantTotal {
+				t.Errorf("Total = %d, want %d", output.Summary.Total, tt.wantTotal)
+			}
+			if output.Summary.Broken != tt.wantBroken {
+				t.Errorf("Broken = %d, want %d", output.Summary.Broken, tt.wantBroken)
+			}
+			if output.Summary.Success != tt.wantSuccess {
+				t.Errorf("Success = %d, want %d", output.Summary.Success, tt.wantSuccess)
+			}
+
+			// Verify results count
+			if len(output.Results) != len(tt.results) {
+				t.Errorf("Results count = %d, want %d", len(output.Results), len(tt.results))
+			}
+		})
+	}
+}
+
+func TestOutputJSON_ErrorHandling(t *testing.T) {
+	results := []linkcheck.LinkResult{
+		{
+			URL:       "https://example.com/error",
+			Status:    0,
+			Error:     errors.New("network timeout"),
+			Severity:  linkcheck.SeverityError,
+			SourceURL: "https://example.com",
+		},
+	}
+
+	// Capture stdout
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	outputJSON(results, 1, false, false)
+
+	w.Close()
+	os.Stdout = oldStdout
+
+	var buf bytes.Buffer
+	io.Copy(&buf, r)
+
+	var output JSONOutput
+	if err := json.Unmarshal(buf.Bytes(), &output); err != nil {
+		t.Fatalf("Failed to parse JSON: %v", err)
+	}
+
+	// Verify error is included in JSON
+	if output.Results[0].Error == nil {
+		t.Error("Expected error field to be present")
+	}
+
+	if *output.Results[0].Error != "network timeout" {
+		t.Errorf("Error = %s, want 'network timeout'", *output.Results[0].Error)
+	}
+
+	if !output.Results[0].Broken {
+		t.Error("Expected broken to be true")
+	}
+}
+
+func TestOutputHuman_NormalMode(t *testing.T) {
+	results := []linkcheck.LinkResult{
+		{URL: "https://example.com", Status: 200, Severity: linkcheck.SeverityOK},
+		{URL: "https://example.com/404", Status: 404, Severity: linkcheck.SeverityError, SourceURL: "https://example.com"},
+	}
+
+	// Capture stdout
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	outputHuman(results, 1, false, false, false, linkcheck.SeverityError)
+
+	w.Close()
+	os.Stdout = oldStdout
+
+	var buf bytes.Buffer
+	io.Copy(&buf, r)
+	output := buf.String()
+
+	// Verify output contains key elements
+	if !strings.Contains(output, "Results:") {
+		t.Error("Expected 'Results:' header")
+	}
+
+	if !strings.Contains(output, "✓ [200] https://example.com") {
+		t.Error("Expected successful link output")
+	}
+
+	if !strings.Contains(output, "✗ [404] https://example.com/404") {
+		t.Error("Expected broken link output")
+	}
+
+	if !strings.Contains(output, "Summary:") {
+		t.Error("Expected summary")
+	}
+}
+
+func TestOutputHuman_QuietMode(t *testing.T) {
+	results := []linkcheck.LinkResult{
+		{URL: "https://example.com", Status: 200, Severity: linkcheck.SeverityOK},
+		{URL: "https://example.com/404", Status: 404, Severity: linkcheck.SeverityError, SourceURL: "https://example.com"},
+		{URL: "https://error.com", Status: 0, Error: errors.New("timeout"), Severity: linkcheck.SeverityError},
+	}
+
+	// Capture stdout
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	outputHuman(results, 2, false, false, true, linkcheck.SeverityError)
+
+	w.Close()
+	os.Stdout = oldStdout
+
+	var buf bytes.Buffer
+	io.Copy(&buf, r)
+	output := buf.String()
+
+	// In quiet mode, should NOT contain success messages
+	if strings.Contains(output, "✓ [200]") {
+		t.Error("Quiet mode should not show successful links")
+	}
+
+	if strings.Contains(output, "Results:") {
+		t.Error("Quiet mode should not show 'Results:' header")
+	}
+
+	if strings.Contains(output, "Summary:") {
+		t.Error("Quiet mode should not show summary")
+	}
+
+	// Should still show broken links
+	if !strings.Contains(output, "✗ [404]") {
+		t.Error("Quiet mode should show broken links")
+	}
+
+	if !strings.Contains(output, "✗ [error]") {
+		t.Error("Quiet mode should show error links")
+	}
+}
+
+func TestOutputHuman_WithError(t *testing.T) {
+	results := []linkcheck.LinkResult{
+		{
+			URL:       "https://example.com/timeout",
+			Status:    0,
+			Error:     errors.New("connection timeout"),
+			Severity:  linkcheck.SeverityError,
+			SourceURL: "https://example.com",
+		},
+	}
+
+	// Capture stdout
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	outputHuman(results, 1, false, false, false, linkcheck.SeverityError)
+
+	w.Close()
+	os.Stdout = oldStdout
+
+	var buf bytes.Buffer
+	io.Copy(&buf, r)
+	output := buf.String()
+
+	// Verify error output format
+	if !strings.Contains(output, "✗ [error]") {
+		t.Error("Expected error marker")
+	}
+
+	if !strings.Contains(output, "connection timeout") {
+		t.Error("Expected error message")
+	}
+
+	if !strings.Contains(output, "Source: https://example.com") {
+		t.Error("Expected source URL")
+	}
+}
+
+func TestOutputJSON_Truncated(t *testing.T) {
+	results := []linkcheck.LinkResult{
+		{URL: "https://example.com", Status: 200, Severity: linkcheck.SeverityOK},
+	}
+
+	// Capture stdout
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	outputJSON(results, 0, true, false)
+
+	w.Close()
+	os.Stdout = oldStdout
+
+	var buf bytes.Buffer
+	io.Copy(&buf, r)
+
+	var output JSONOutput
+	if err := json.Unmarshal(buf.Bytes(), &output); err != nil {
+		t.Fatalf("Failed to parse JSON: %v", err)
+	}
+
+	if !output.Summary.Truncated {
+		t.Error("Expected truncated to be true")
+	}
+}
+
+func TestOutputHuman_Truncated(t *testing.T) {
+	results := []linkcheck.LinkResult{
+		{URL: "https://example.com", Status: 200, Severity: linkcheck.SeverityOK},
+	}
+
+	// Capture stdout
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	outputHuman(results, 0, true, false, true, linkcheck.SeverityError)
+
+	w.Close()
+	os.Stdout = oldStdout
+
+	var buf bytes.Buffer
+	io.Copy(&buf, r)
+	output := buf.String()
+
+	// truncation notice is shown even in quiet mode
+	if !strings.Contains(output, "Crawl truncated") {
+		t.Error("Expected truncation notice")
+	}
+}
+
+func TestOutputJSON_Incomplete(t *testing.T) {
+	results := []linkcheck.LinkResult{
+		{URL: "https://example.com", Status: 200, Severity: linkcheck.SeverityOK},
+	}
+
+	// Capture stdout
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	outputJSON(results, 0, false, true)
+
+	w.Close()
+	os.Stdout = oldStdout
+
+	var buf bytes.Buffer
+	io.Copy(&buf, r)
+
+	var output JSONOutput
+	if err := json.Unmarshal(buf.Bytes(), &output); err != nil {
+		t.Fatalf("Failed to parse JSON: %v", err)
+	}
+
+	if !output.Summary.Incomplete || output.Summary.Total != 1 {
+		t.Errorf("Summary = %+v, want the partial result marked incomplete", output.Summary)
+	}
+}
+
+func TestOutputHuman_Incomplete(t *testing.T) {
+	results := []linkcheck.LinkResult{
+		{URL: "https://example.com", Status: 200, Severity: linkcheck.SeverityOK},
+	}
+
+	// Capture stdout
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	outputHuman(results, 0, false, true, true, linkcheck.SeverityError)
+
+	w.Close()
+	os.Stdout = oldStdout
+
+	var buf bytes.Buffer
+	io.Copy(&buf, r)
+
+	// the notice is shown even in quiet mode
+	if !strings.Contains(buf.String(), "Incomplete") {
+		t.Error("Expected incomplete notice")
+	}
+}
+
+func TestStreamJSONL(t *testing.T) {
+	var buf bytes.Buffer
+	stream := streamJSONL(&buf)
+	stream(linkcheck.LinkResult{URL: "https://example.com", Status: 200, Severity: linkcheck.SeverityOK})
+	stream(linkcheck.LinkResult{URL: "https://example.com/404", Status: 404, Severity: linkcheck.SeverityError})
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected one line per result, got %q", buf.String())
+	}
+	for i, want := range []string{"https://example.com", "https://example.com/404"} {
+		var result JSONResult
+		if err := json.Unmarshal([]byte(lines[i]), &result); err != nil {
+			t.Fatalf("Failed to parse line %d: %v", i+1, err)
+		}
+		if result.URL != want {
+			t.Errorf("line %d URL = %q, want %q", i+1, result.URL, want)
+		}
+	}
+}
+
+func TestOutputJSONLSummary(t *testing.T) {
+	results := []linkcheck.LinkResult{
+		{URL: "https://example.com", Status: 200, Severity: linkcheck.SeverityOK},
+		{URL: "https://example.com/404", Status: 404, Severity: linkcheck.SeverityError},
+	}
+
+	// Capture stdout
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	outputJSONLSummary(results, 1, false, false)
+
+	w.Close()
+	os.Stdout = oldStdout
+
+	var buf bytes.Buffer
+	io.Copy(&buf, r)
+
+	if strings.Count(buf.String(), "\n") != 1 {
+		t.Errorf("Expected a single line, got %q", buf.String())
+	}
+	var output JSONLSummary
+	if err := json.Unmarshal(buf.Bytes(), &output); err != nil {
+		t.Fatalf("Failed to parse JSON: %v", err)
+	}
+	if output.Summary.Total != 2 || output.Summary.Errors != 1 {
+		t.Errorf("Summary = %+v, want 2 results with 1 error", output.Summary)
+	}
+}
+
+func TestOutputJSON_Skipped(t *testing.T) {
+	results := []linkcheck.LinkResult{
+		{URL: "https://example.com", Status: 200, Severity: linkcheck.SeverityOK},
+		{URL: "https://example.com/private", Skipped: linkcheck.SkipRobots, SourceURL: "https://example.com"},
+	}
+
+	// Capture stdout
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	outputJSON(results, 0, false, false)
+
+	w.Close()
+	os.Stdout = oldStdout
+
+	var buf bytes.Buffer
+	io.Copy(&buf, r)
+
+	var output JSONOutput
+	if err := json.Unmarshal(buf.Bytes(), &output); err != nil {
+		t.Fatalf("Failed to parse JSON: %v", err)
+	}
+
+	if output.Summary.Skipped != 1 {
+		t.Errorf("Skipped = %d, want 1", output.Summary.Skipped)
+	}
+	if output.Summary.Success != 1 {
+		t.Errorf("Success = %d, want 1", output.Summary.Success)
+	}
+	if output.Results[1].Skipped != linkcheck.SkipRobots {
+		t.Errorf("Results[1].Skipped = %q, want %q", output.Results[1].Skipped, linkcheck.SkipRobots)
+	}
+}
+
+func TestOutputHuman_Skipped(t *testing.T) {
+	results := []linkcheck.LinkResult{
+		{URL: "https://example.com", Status: 200, Severity: linkcheck.SeverityOK},
+		{URL: "https://example.com/private", Skipped: linkcheck.SkipRobots},
+	}
+
+	// Capture stdout
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	outputHuman(results, 0, false, false, false, linkcheck.SeverityError)
+
+	w.Close()
+	os.Stdout = oldStdout
+
+	var buf bytes.Buffer
+	io.Copy(&buf, r)
+	output := buf.String()
+
+	if !strings.Contains(output, "⊘ [skipped (robots)] https://example.com/private") {
+		t.Error("Expected skipped link output")
+	}
+
+	if !strings.Contains(output, "1 checked, 0 broken, 1 skipped") {
+		t.Errorf("Expected skipped count in summary, got:\n%s", output)
+	}
+}
+
+func TestOutputHuman_Excluded(t *testing.T) {
+	results := []linkcheck.LinkResult{
+		{URL: "https://example.com", Status: 200, Severity: linkcheck.SeverityOK},
+		{URL: "http://localhost:8080/", Skipped: linkcheck.SkipExcluded, Rule: "exclude http://localhost*"},
+		{URL: "https://example.com/private", Skipped: linkcheck.SkipRobots},
+	}
+
+	// Capture stdout
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	outputHuman(results, 0, false, false, false, linkcheck.SeverityError)
+
+	w.Close()
+	os.Stdout = oldStdout
+
+	var buf bytes.Buffer
+	io.Copy(&buf, r)
+	output := buf.String()
+
+	if !strings.Contains(output, "⊘ [excluded] http://localhost:8080/\n  └─ Rule: exclude http://localhost*") {
+		t.Errorf("Expected excluded link with its rule, got:\n%s", output)
+	}
+
+	if !strings.Contains(output, "1 checked, 0 broken, 1 skipped, 1 excluded") {
+		t.Errorf("Expected excluded count in summary, got:\n%s", output)
+	}
+}
+
+func TestOutputJSON_Excluded(t *testing.T) {
+	results := []linkcheck.LinkResult{
+		{URL: "https://example.com", Status: 200, Severity: linkcheck.SeverityOK},
+		{URL: "http://localhost:8080/", Skipped: linkcheck.SkipExcluded, Rule: "exclude http://localhost*"},
+	}
+
+	// Capture stdout
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	outputJSON(results, 0, false, false)
+
+	w.Close()
+	os.Stdout = oldStdout
+
+	var buf bytes.Buffer
+	io.Copy(&buf, r)
+
+	var output JSONOutput
+	if err := json.Unmarshal(buf.Bytes(), &output); err != nil {
+		t.Fatalf("Failed to parse JSON: %v", err)
+	}
+
+	if output.Summary.Excluded != 1 || output.Summary.Skipped != 0 || output.Summary.Success != 1 {
+		t.Errorf("Summary = %+v, want 1 excluded, 0 skipped and 1 success", output.Summary)
+	}
+	if output.Results[1].Skipped != linkcheck.SkipExcluded || output.Results[1].Rule != "exclude http://localhost*" {
+		t.Errorf("Results[1] = %+v, want excluded with its rule", output.Results[1])
+	}
+}
+
+func TestOutputHuman_Suppressed(t *testing.T) {
+	result := linkcheck.LinkResult{URL: "https://intranet.example.com/", Skipped: linkcheck.SkipSuppressed}
+	result.AddLocation(linkcheck.Location{Source: "docs/setup.md", Line: 12, Column: 5})
+	results := []linkcheck.LinkResult{
+		{URL: "https://example.com", Status: 200, Severity: linkcheck.SeverityOK},
+		result,
+	}
+
+	// Capture stdout
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	outputHuman(results, 0, false, false, false, linkcheck.SeverityError)
+
+	w.Close()
+	os.Stdout = oldStdout
+
+	var buf bytes.Buffer
+	io.Copy(&buf, r)
+	output := buf.String()
+
+	if !strings.Contains(output, "⊘ [suppressed] https://intranet.example.com/\n  └─ Source: docs/setup.md:12:5") {
+		t.Errorf("Expected suppressed link with its source, got:\n%s", output)
+	}
+
+	if !strings.Contains(output, "1 checked, 0 broken, 1 suppressed") {
+		t.Errorf("Expected suppressed count in summary, got:\n%s", output)
+	}
+}
+
+func TestOutputJSON_Suppressed(t *testing.T) {
+	results := []linkcheck.LinkResult{
+		{URL: "https://example.com", Status: 200, Severity: linkcheck.SeverityOK},
+		{URL: "https://intranet.example.com/", Skipped: linkcheck.SkipSuppressed, SourceURL: "docs/setup.md", Line: 12, Column: 5},
+	}
+
+	// Capture stdout
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	outputJSON(results, 0, false, false)
+
+	w.Close()
+	os.Stdout = oldStdout
+
+	var buf bytes.Buffer
+	io.Copy(&buf, r)
+
+	var output JSONOutput
+	if err := json.Unmarshal(buf.Bytes(), &output); err != nil {
+		t.Fatalf("Failed to parse JSON: %v", err)
+	}
+
+	if output.Summary.Suppressed != 1 || output.Summary.Skipped != 0 || output.Summary.Success != 1 {
+		t.Errorf("Summary = %+v, want 1 suppressed, 0 skipped and 1 success", output.Summary)
+	}
+	if output.Results[1].Skipped != linkcheck.SkipSuppressed || output.Results[1].Line != 12 {
+		t.Errorf("Results[1] = %+v, want suppressed at line 12", output.Results[1])
+	}
+}
+
+func TestOutputHuman_Attempts(t *testing.T) {
+	results := []linkcheck.LinkResult{
+		{URL: "https://example.com/flaky", Status: 200, Attempts: 3},
+		{URL: "https://example.com/down", Status: 503, Severity: linkcheck.SeverityError, Attempts: 4},
+	}
+
+	// Capture stdout
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	outputHuman(results, 1, false, false, false, linkcheck.SeverityError)
+
+	w.Close()
+	os.Stdout = oldStdout
+
+	var buf bytes.Buffer
+	io.Copy(&buf, r)
+	output := buf.String()
+
+	if !strings.Contains(output, "✓ [200] https://example.com/flaky (after 3 attempts)") {
+		t.Error("Expected attempt count for flaky link")
+	}
+
+	if !strings.Contains(output, "Attempts: 4") {
+		t.Error("Expected attempt count for broken link")
+	}
+}
+
+func TestOutputJSON_Redirects(t *testing.T) {
+	results := []linkcheck.LinkResult{
+		{
+			URL:      "http://example.com/old",
+			Status:   200,
+			Warning:  "permanent redirect, update the link",
+			Severity: linkcheck.SeverityWarning,
+			Redirects: []linkcheck.Redirect{
+				{URL: "http://example.com/old", Status: 301, Location: "https://example.com/new", Duration: 12 * time.Millisecond},
+			},
+		},
+		{URL: "https://example.com", Status: 200, Severity: linkcheck.SeverityOK},
+	}
+
+	// Capture stdout
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	outputJSON(results, 0, false, false)
+
+	w.Close()
+	os.Stdout = oldStdout
+
+	var buf bytes.Buffer
+	io.Copy(&buf, r)
+
+	var output JSONOutput
+	if err := json.Unmarshal(buf.Bytes(), &output); err != nil {
+		t.Fatalf("Failed to parse JSON: %v", err)
+	}
+
+	if output.Summary.Warnings != 1 || output.Summary.Success != 1 {
+		t.Errorf("Summary = %+v, want 1 warning and 1 success", output.Summary)
+	}
+	if output.Results[0].Severity != "warning" || output.Results[0].Broken {
+		t.Errorf("Results[0] severity = %q, broken = %v, want a warning", output.Results[0].Severity, output.Results[0].Broken)
+	}
+
+	redirects := output.Results[0].Redirects
+	if len(redirects) != 1 {
+		t.Fatalf("Expected 1 redirect, got %d", len(redirects))
+	}
+	want := JSONRedirect{URL: "http://example.com/old", Status: 301, Location: "https://example.com/new", DurationMs: 12}
+	if redirects[0] != want {
+		t.Errorf("Redirects[0] = %+v, want %+v", redirects[0], want)
+	}
+
+	if output.Results[1].Redirects != nil {
+		t.Error("Expected no redirects for direct link")
+	}
+}
+
+func TestOutputHuman_Redirects(t *testing.T) {
+	results := []linkcheck.LinkResult{
+		{
+			URL:      "http://example.com/old",
+			Status:   200,
+			Warning:  "permanent redirect, update the link",
+			Severity: linkcheck.SeverityWarning,
+			Redirects: []linkcheck.Redirect{
+				{URL: "http://example.com/old", Status: 308, Location: "https://example.com/new", Duration: 5 * time.Millisecond},
+			},
+		},
+	}
+
+	// Capture stdout
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	outputHuman(results, 0, false, false, false, linkcheck.SeverityError)
+
+	w.Close()
+	os.Stdout = oldStdout
+
+	var buf bytes.Buffer
+	io.Copy(&buf, r)
+	output := buf.String()
+
+	if !strings.Contains(output, "⚠ [200] http://example.com/old") {
+		t.Error("Expected warning marker")
+	}
+	if !strings.Contains(output, "308 → https://example.com/new (5ms)") {
+		t.Errorf("Expected redirect trail, got:\n%s", output)
+	}
+	if !strings.Contains(output, "1 warnings") {
+		t.Error("Expected warning count in summary")
+	}
+}
+
+func TestOutputHuman_Severities(t *testing.T) {
+	results := []linkcheck.LinkResult{
+		{URL: "https://example.com/ok", Status: 200, Severity: linkcheck.SeverityOK},
+		{URL: "https://example.com/moved", Status: 200, Severity: linkcheck.SeverityInfo, Info: "temporary redirect to https://example.com/new"},
+		{URL: "https://example.com/slow", Status: 200, Severity: linkcheck.SeverityWarning, Warning: "slow response, took 6s"},
+		{URL: "https://example.com/gone", Status: 404, Severity: linkcheck.SeverityError},
+	}
+
+	capture := func(quiet bool, failOn linkcheck.Severity) string {
+		oldStdout := os.Stdout
+		r, w, _ := os.Pipe()
+		os.Stdout = w
+
+		outputHuman(results, 1, false, false, quiet, failOn)
+
+		w.Close()
+		os.Stdout = oldStdout
+
+		var buf bytes.Buffer
+		io.Copy(&buf, r)
+		return buf.String()
+	}
+
+	output := capture(false, linkcheck.SeverityError)
+	for _, want := range []string{
+		"✓ [200] https://example.com/ok",
+		"ℹ [200] https://example.com/moved\n  └─ Info: temporary redirect to https://example.com/new",
+		"⚠ [200] https://example.com/slow\n  └─ Warning: slow response, took 6s",
+		"✗ [404] https://example.com/gone",
+		"Summary: 4 checked, 1 broken, 1 warnings, 1 info",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %q, got:\n%s", want, output)
+		}
+	}
+
+	// quiet mode shows what fails the run
+	if output := capture(true, linkcheck.SeverityError); strings.Contains(output, "slow") || !strings.Contains(output, "gone") {
+		t.Errorf("quiet -fail-on error: got:\n%s", output)
+	}
+	if output := capture(true, linkcheck.SeverityWarning); !strings.Contains(output, "slow") || strings.Contains(output, "moved") {
+		t.Errorf("quiet -fail-on warning: got:\n%s", output)
+	}
+}
+
+func TestOutputJSON_Severities(t *testing.T) {
+	results := []linkcheck.LinkResult{
+		{URL: "https://example.com/ok", Status: 200, Severity: linkcheck.SeverityOK, Duration: 120 * time.Millisecond},
+		{URL: "https://example.com/moved", Status: 200, Severity: linkcheck.SeverityInfo, Info: "temporary redirect to https://example.com/new"},
+		{URL: "https://example.com/slow", Status: 200, Severity: linkcheck.SeverityWarning, Warning: "slow response, took 6s"},
+		{URL: "https://example.com/gone", Status: 404, Severity: linkcheck.SeverityError},
+		{URL: "https://example.com/private", Skipped: linkcheck.SkipRobots},
+	}
+
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	outputJSON(results, 1, false, false)
+
+	w.Close()
+	os.Stdout = oldStdout
+
+	var buf bytes.Buffer
+	io.Copy(&buf, r)
+
+	var output JSONOutput
+	if err := json.Unmarshal(buf.Bytes(), &output); err != nil {
+		t.Fatalf("Failed to parse JSON: %v", err)
+	}
+
+	want := JSONSummary{Total: 5, Broken: 1, Success: 2, OK: 1, Info: 1, Warnings: 1, Errors: 1, Skipped: 1}
+	if !reflect.DeepEqual(output.Summary, want) {
+		t.Errorf("Summary = %+v, want %+v", output.Summary, want)
+	}
+	if output.Results[0].DurationMs != 120 {
+		t.Errorf("Results[0].DurationMs = %d, want 120", output.Results[0].DurationMs)
+	}
+	if output.Results[1].Severity != "info" || output.Results[1].Info == "" {
+		t.Errorf("Results[1] = %+v, want an info result with its detail", output.Results[1])
+	}
+}
+
+func TestOutputJSON_ErrorKinds(t *testing.T) {
+	results := []linkcheck.LinkResult{
+		{URL: "https://gone.invalid", Error: errors.New("no such host"), ErrorKind: linkcheck.ErrorDNSNotFound, Severity: linkcheck.SeverityError},
+		{URL: "https://slow.example.com", Error: errors.New("deadline exceeded"), ErrorKind: linkcheck.ErrorTimeout, Severity: linkcheck.SeverityError},
+		{URL: "https://slower.example.com", Error: errors.New("deadline exceeded"), ErrorKind: linkcheck.ErrorTimeout, Severity: linkcheck.SeverityError},
+		{URL: "https://example.com/404", Status: 404, Severity: linkcheck.SeverityError},
+	}
+
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	outputJSON(results, 4, false, false)
+
+	w.Close()
+	os.Stdout = oldStdout
+
+	var buf bytes.Buffer
+	io.Copy(&buf, r)
+
+	var output JSONOutput
+	if err := json.Unmarshal(buf.Bytes(), &output); err != nil {
+		t.Fatalf("Failed to parse JSON: %v", err)
+	}
+
+	if output.Results[0].ErrorKind != "dns_not_found" || output.Results[3].ErrorKind != "" {
+		t.Errorf("ErrorKind = %q, %q, want dns_not_found and none for a status error",
+			output.Results[0].ErrorKind, output.Results[3].ErrorKind)
+	}
+	want := map[linkcheck.ErrorKind]int{linkcheck.ErrorDNSNotFound: 1, linkcheck.ErrorTimeout: 2}
+	if !reflect.DeepEqual(output.Summary.ErrorKinds, want) {
+		t.Errorf("Summary.ErrorKinds = %v, want %v", output.Summary.ErrorKinds, want)
+	}
+}
+
+func TestOutputHuman_ErrorKinds(t *testing.T) {
+	results := []linkcheck.LinkResult{
+		{URL: "https://gone.invalid", Error: errors.New("no such host"), ErrorKind: linkcheck.ErrorDNSNotFound, Severity: linkcheck.SeverityError},
+		{URL: "https://slow.example.com", Error: errors.New("deadline exceeded"), ErrorKind: linkcheck.ErrorTimeout, Severity: linkcheck.SeverityError},
+		{URL: "https://slower.example.com", Error: errors.New("deadline exceeded"), ErrorKind: linkcheck.ErrorTimeout, Severity: linkcheck.SeverityError},
+	}
+
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	outputHuman(results, 3, false, false, false, linkcheck.SeverityError)
+
+	w.Close()
+	os.Stdout = oldStdout
+
+	var buf bytes.Buffer
+	io.Copy(&buf, r)
+	output := buf.String()
+
+	for _, want := range []string{
+		"✗ [dns_not_found] https://gone.invalid\n  └─ Error: no such host",
+		"✗ [timeout] https://slow.example.com",
+		"Failed requests: 1 dns_not_found, 2 timeout",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %q, got:\n%s", want, output)
+		}
+	}
+}
+
+func TestOutputHuman_BrokenFragment(t *testing.T) {
+	results := []linkcheck.LinkResult{
+		{
+			URL:       "https://example.com/guide#install",
+			Status:    200,
+			Severity:  linkcheck.SeverityError,
+			Fragment:  "install",
+			SourceURL: "https://example.com",
+		},
+	}
+
+	// Capture stdout
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	outputHuman(results, 1, false, false, true, linkcheck.SeverityError)
+
+	w.Close()
+	os.Stdout = oldStdout
+
+	var buf bytes.Buffer
+	io.Copy(&buf, r)
+	output := buf.String()
+
+	if !strings.Contains(output, "✗ [broken fragment] https://example.com/guide#install") {
+		t.Error("Expected broken fragment marker")
+	}
+	if !strings.Contains(output, "Fragment: #install not found on https://example.com/guide") {
+		t.Errorf("Expected page URL and fragment, got:\n%s", output)
+	}
+}
+
+func TestOutputHuman_Element(t *testing.T) {
+	results := []linkcheck.LinkResult{
+		{
+			URL:       "https://example.com/missing.png",
+			Status:    404,
+			Severity:  linkcheck.SeverityError,
+			SourceURL: "https://example.com",
+			Element:   "img",
+			Attribute: "srcset",
+		},
+	}
+
+	// Capture stdout
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	outputHuman(results, 1, false, false, true, linkcheck.SeverityError)
+
+	w.Close()
+	os.Stdout = oldStdout
+
+	var buf bytes.Buffer
+	io.Copy(&buf, r)
+	output := buf.String()
+
+	if !strings.Contains(output, "Source: https://example.com (img[srcset])") {
+		t.Errorf("Expected source with element and attribute, got:\n%s", output)
+	}
+}
+
+func TestOutputHuman_Position(t *testing.T) {
+	results := []linkcheck.LinkResult{
+		{
+			URL:       "https://example.com/missing",
+			Status:    404,
+			Severity:  linkcheck.SeverityError,
+			SourceURL: "docs/guide.md",
+			Line:      12,
+			Column:    5,
+		},
+		{
+			URL:       "https://example.com/missing.png",
+			Status:    404,
+			Severity:  linkcheck.SeverityError,
+			SourceURL: "https://example.com/page",
+			Element:   "img",
+			Attribute: "src",
+			Line:      3,
+			Column:    14,
+		},
+	}
+
+	// Capture stdout
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	outputHuman(results, 2, false, false, true, linkcheck.SeverityError)
+
+	w.Close()
+	os.Stdout = oldStdout
+
+	var buf bytes.Buffer
+	io.Copy(&buf, r)
+	output := buf.String()
+
+	if !strings.Contains(output, "Source: docs/guide.md:12:5\n") {
+		t.Errorf("Expected file:line:column source, got:\n%s", output)
+	}
+	if !strings.Contains(output, "Source: https://example.com/page:3:14 (img[src])") {
+		t.Errorf("Expected page position and element, got:\n%s", output)
+	}
+}
+
+func TestOutputHuman_Locations(t *testing.T) {
+	result := linkcheck.LinkResult{URL: "https://example.com/missing", Status: 404, Severity: linkcheck.SeverityError}
+	result.AddLocation(linkcheck.Location{Source: "a.md", Line: 1, Column: 5})
+	result.AddLocation(linkcheck.Location{Source: "b.md", Line: 7, Column: 2})
+
+	// Capture stdout
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	outputHuman([]linkcheck.LinkResult{result}, 1, false, false, true, linkcheck.SeverityError)
+
+	w.Close()
+	os.Stdout = oldStdout
+
+	var buf bytes.Buffer
+	io.Copy(&buf, r)
+	output := buf.String()
+
+	if strings.Count(output, "✗ [404] https://example.com/missing") != 1 {
+		t.Errorf("Expected the link to be reported once, got:\n%s", output)
+	}
+	if !strings.Contains(output, "Sources (2):\n       a.md:1:5\n       b.md:7:2\n") {
+		t.Errorf("Expected every source, got:\n%s", output)
+	}
+}
+
+func TestOutputJSON_Locations(t *testing.T) {
+	result := linkcheck.LinkResult{URL: "https://example.com/missing", Status: 404, Severity: linkcheck.SeverityError}
+	result.AddLocation(linkcheck.Location{Source: "https://example.com/a", Line: 3, Column: 9, Element: "a", Attribute: "href"})
+	result.AddLocation(linkcheck.Location{Source: "https://example.com/b", Line: 4, Column: 1, Element: "a", Attribute: "href"})
+
+	// Capture stdout
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	outputJSON([]linkcheck.LinkResult{result}, 1, false, false)
+
+	w.Close()
+	os.Stdout = oldStdout
+
+	var output JSONOutput
+	if err := json.NewDecoder(r).Decode(&output); err != nil {
+		t.Fatalf("Failed to decode JSON: %v", err)
+	}
+
+	got := output.Results[0]
+	if got.SourceURL != "https://example.com/a" || got.Line != 3 {
+		t.Errorf("source = %s:%d, want the first location", got.SourceURL, got.Line)
+	}
+	if len(got.Locations) != 2 || got.Locations[1].Source != "https://example.com/b" || got.Locations[1].Element != "a" {
+		t.Errorf("locations = %+v, want both locations", got.Locations)
+	}
+}
+
+func TestJSONResult_Serialization(t *testing.T) {
+	errMsg := "test error"
+	result := JSONResult{
+		URL:       "https://example.com",
+		Status:    http.StatusNotFound,
+		Error:     &errMsg,
+		Broken:    true,
+		SourceURL: "https://source.com",
+		Attempts:  3,
+		Fragment:  "section",
+		Element:   "img",
+		Attribute: "src",
+		Line:      7,
+		Column:    21,
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatalf("Failed to marshal JSON: %v", err)
+	}
+
+	var decoded JSONResult
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Failed to unmarshal JSON: %v", err)
+	}
+
+	if decoded.URL != result.URL {
+		t.Errorf("URL = %s, want %s", decoded.URL, result.URL)
+	}
+
+	if decoded.Status != result.Status {
+		t.Errorf("Status = %d, want %d", decoded.Status, result.Status)
+	}
+
+	if decoded.Error == nil || *decoded.Error != *result.Error {
+		t.Errorf("Error mismatch")
+	}
+
+	if decoded.Broken != result.Broken {
+		t.Errorf("Broken = %v, want %v", decoded.Broken, result.Broken)
+	}
+
+	if decoded.Attempts != result.Attempts {
+		t.Errorf("Attempts = %d, want %d", decoded.Attempts, result.Attempts)
+	}
+
+	if decoded.Fragment != result.Fragment {
+		t.Errorf("Fragment = %q, want %q", decoded.Fragment, result.Fragment)
+	}
+
+	if decoded.Element != result.Element || decoded.Attribute != result.Attribute {
+		t.Errorf("Element = %q[%q], want %q[%q]", decoded.Element, decoded.Attribute, result.Element, result.Attribute)
+	}
+
+	if decoded.Line != result.Line || decoded.Column != result.Column {
+		t.Errorf("Position = %d:%d, want %d:%d", decoded.Line, decoded.Column, result.Line, result.Column)
+	}
+}
+
+func TestJSONResult_NilError(t *testing.T) {
+	result := JSONResult{
+		URL:    "https://example.com",
+		Status: http.StatusOK,
+		Error:  nil,
+		Broken: false,
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatalf("Failed to marshal JSON: %v", err)
+	}
+
+	// Verify error field is omitted when nil
+	if strings.Contains(string(data), "error") {
+		t.Error("Expected error field to be omitted when nil")
+	}
+}